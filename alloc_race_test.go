@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package glog
+
+// raceEnabled reports whether tests run with -race, where sync.Pool drops items at random.
+const raceEnabled = true
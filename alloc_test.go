@@ -0,0 +1,72 @@
+package glog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Calls through the Logger interface always heap-allocate their variadic argument slice at the call site, because
+// interface method arguments escape. The tests below check that glog adds nothing on top of that: calls without
+// arguments do not allocate, and calls with constant arguments allocate only that one slice.
+
+func TestAllocs_DisabledLevel(t *testing.T) {
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Debug("disabled")
+		log.Trace("disabled")
+		log.Log(DEBUG, "disabled")
+		log.LogOnce(DEBUG, "key", "disabled")
+		Debug("disabled")
+		Trace("disabled")
+	})
+	assert.Equal(t, 0.0, allocs)
+
+	allocs = testing.AllocsPerRun(100, func() {
+		log.Debug("disabled %s %d", "x", 42)
+	})
+	assert.Equal(t, 1.0, allocs, "only the caller's argument slice")
+
+	named := Named(log, "x")
+	field := String("k", "v")
+	allocs = testing.AllocsPerRun(100, func() {
+		log.Log(DEBUG, "disabled", field)
+		log.Debug("disabled", field)
+		named.Log(DEBUG, "disabled", field)
+	})
+	assert.Equal(t, 6.0, allocs, "only the caller's argument slice and boxed Field, no split fields")
+}
+
+func TestAllocs_GetOutput(t *testing.T) {
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = log.GetOutput(DEBUG)
+		_ = log.DebugLogger()
+		_ = log.TraceLogger()
+	})
+
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestAllocs_EnabledText(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool does not reuse buffers reliably under -race")
+	}
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+	log.(ColorSetter).SetColor(ColorAlways)
+	log.Info("warm up the buffer pool")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Info("enabled")
+		log.Warn("enabled")
+	})
+	assert.Equal(t, 0.0, allocs)
+
+	allocs = testing.AllocsPerRun(100, func() {
+		log.Info("enabled %s %d", "x", 42)
+	})
+	assert.Equal(t, 1.0, allocs, "only the caller's argument slice")
+}
@@ -0,0 +1,176 @@
+package glog
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"testing"
+)
+
+// discardStd points Create's stdout/stderr at ioutil.Discard for the duration of the benchmark.
+func discardStd(b *testing.B) {
+	out, err := _stdout, _stderr
+	_stdout = log.New(ioutil.Discard, "", log.LstdFlags)
+	_stderr = log.New(ioutil.Discard, "", log.LstdFlags)
+	b.Cleanup(func() {
+		_stdout, _stderr = out, err
+	})
+}
+
+func BenchmarkCreate_Info(b *testing.B) {
+	discardStd(b)
+	logger := Create(INFO)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("request %d handled", i)
+	}
+}
+
+func BenchmarkNewWithWriters(b *testing.B) {
+	levels := []LogLevel{INFO, WARN}
+	for _, level := range levels {
+		b.Run(level.String()[1:], func(b *testing.B) {
+			logger := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Log(level, "request %d handled", i)
+			}
+		})
+	}
+}
+
+func BenchmarkNewWithWriters_Logfmt(b *testing.B) {
+	w := Encoded(ioutil.Discard, LogfmtEncoder{})
+	logger := NewWithWriters(w, w, INFO)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("request %d handled", i)
+	}
+}
+
+func BenchmarkLevelRouter_Routed(b *testing.B) {
+	router := NewLevelRouter(map[LogLevel]io.Writer{
+		DEBUG: ioutil.Discard,
+		INFO:  ioutil.Discard,
+		WARN:  ioutil.Discard,
+		ERROR: ioutil.Discard,
+	}, DEBUG)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		router.Info("request %d handled", i)
+	}
+}
+
+func BenchmarkLevelRouter_Unrouted(b *testing.B) {
+	discardStd(b)
+	router := NewLevelRouter(map[LogLevel]io.Writer{DEBUG: ioutil.Discard}, DEBUG)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.Info("request %d handled", i)
+	}
+}
+
+func BenchmarkComposite(b *testing.B) {
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("loggers=%d", n), func(b *testing.B) {
+			chain := make([]Logger, n)
+			for i := range chain {
+				chain[i] = NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+			}
+			logger := Composite(chain[0], chain[1:]...)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Info("request %d handled", i)
+			}
+		})
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	loggers := map[string]Logger{
+		"logger":    NewWithWriters(ioutil.Discard, ioutil.Discard, WARN),
+		"router":    NewLevelRouter(map[LogLevel]io.Writer{DEBUG: ioutil.Discard}, WARN),
+		"composite": Composite(NewWithWriters(ioutil.Discard, ioutil.Discard, WARN), NewWithWriters(ioutil.Discard, ioutil.Discard, WARN)),
+	}
+	for name, logger := range loggers {
+		logger := logger
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Debug("request handled")
+			}
+		})
+	}
+}
+
+func BenchmarkDisabled_Default(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Debug("request handled")
+	}
+}
+
+func BenchmarkParallel(b *testing.B) {
+	loggers := map[string]Logger{
+		"logger": NewWithWriters(ioutil.Discard, ioutil.Discard, INFO),
+		"router": NewLevelRouter(map[LogLevel]io.Writer{INFO: ioutil.Discard, WARN: ioutil.Discard}, INFO),
+		"composite": Composite(
+			NewWithWriters(ioutil.Discard, ioutil.Discard, INFO),
+			NewWithWriters(ioutil.Discard, ioutil.Discard, INFO),
+		),
+		"disabled": NewWithWriters(ioutil.Discard, ioutil.Discard, WARN),
+	}
+	for name, logger := range loggers {
+		logger := logger
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info("request handled")
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLogger_Disabled(b *testing.B) {
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Debug("disabled %s %d", "x", 42)
+	}
+}
+
+func BenchmarkLogger_EnabledText(b *testing.B) {
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Info("enabled %s %d", "x", 42)
+	}
+}
+
+func BenchmarkLogger_GetOutput(b *testing.B) {
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.GetOutput(DEBUG).Printf("disabled")
+	}
+}
+
+func BenchmarkLevelRouter_Thresholds(b *testing.B) {
+	router := NewLevelRouter(nil, DEBUG)
+	router.SetThresholdOutputs(
+		ThresholdOutput{MinLevel: DEBUG, Writer: ioutil.Discard},
+		ThresholdOutput{MinLevel: WARN, Writer: ioutil.Discard},
+		ThresholdOutput{MinLevel: FATAL, Writer: ioutil.Discard},
+	)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			router.Warn("request handled")
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package glog
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// ColorMode selects whether console output colors level prefixes with ANSI escape sequences.
+type ColorMode int
+
+const (
+	// ColorAuto colors only when the writer is a terminal and NO_COLOR is not set. This is the default.
+	ColorAuto ColorMode = iota
+	// ColorAlways colors regardless of the writer and NO_COLOR.
+	ColorAlways
+	// ColorNever disables colors.
+	ColorNever
+)
+
+const colorReset = "\x1b[0m"
+
+// Palette maps a level to the ANSI SGR sequence written before its prefix. Levels without an entry are not colored.
+type Palette map[LogLevel]string
+
+// DefaultPalette is used when SetColor is called without a palette.
+var DefaultPalette = Palette{
+	TRACE: "\x1b[90m",
+	DEBUG: "\x1b[36m",
+	INFO:  "\x1b[32m",
+	WARN:  "\x1b[33m",
+	ERROR: "\x1b[31m",
+	PANIC: "\x1b[1;31m",
+	FATAL: "\x1b[1;35m",
+}
+
+// ColorSetter allows changing console coloring at runtime. Outputs set with SetOutputForLevel/SetOutputs are never colored.
+type ColorSetter interface {
+	SetColor(mode ColorMode, palette ...Palette)
+}
+
+type colorState struct {
+	mu   sync.RWMutex
+	outW io.Writer
+	errW io.Writer
+	// out and err hold the colored prefix per level for each stream; nil when that stream is not colored.
+	out map[LogLevel]string
+	err map[LogLevel]string
+}
+
+func newColorState(out io.Writer, err io.Writer) *colorState {
+	c := &colorState{outW: out, errW: err}
+	c.set(ColorAuto)
+	return c
+}
+
+func (c *colorState) set(mode ColorMode, palette ...Palette) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := DefaultPalette
+	if len(palette) > 0 && palette[0] != nil {
+		p = palette[0]
+	}
+	c.out, c.err = nil, nil
+	if colorEnabled(mode, c.outW) {
+		c.out = p.prefixes()
+	}
+	if colorEnabled(mode, c.errW) {
+		c.err = p.prefixes()
+	}
+}
+
+func (p Palette) prefixes() map[LogLevel]string {
+	prefixes := make(map[LogLevel]string, len(p))
+	for logLevel, seq := range p {
+		prefixes[logLevel] = seq + logLevel.prefix + colorReset
+	}
+	return prefixes
+}
+
+// prefix returns the level prefix for the out (toErr false) or err stream, colored if enabled for it.
+func (c *colorState) prefix(logLevel LogLevel, toErr bool) string {
+	if c == nil {
+		return logLevel.prefix
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	prefixes := c.out
+	if toErr {
+		prefixes = c.err
+	}
+	if prefix, ok := prefixes[logLevel]; ok {
+		return prefix
+	}
+	return logLevel.prefix
+}
+
+func colorEnabled(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
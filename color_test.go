@@ -0,0 +1,112 @@
+package glog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColor_AutoIsOffForNonTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	log.Info("plain")
+
+	assert.Contains(t, buf.String(), " INFO plain")
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestColor_AutoIsOffForRegularFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "color.log"))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.False(t, colorEnabled(ColorAuto, f))
+}
+
+func TestColor_AlwaysColorsPrefix(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	log := NewWithWriters(&out, &errBuf, INFO)
+	log.(ColorSetter).SetColor(ColorAlways)
+
+	log.Info("hello")
+	log.Warn("careful")
+
+	assert.Contains(t, out.String(), "\x1b[32m INFO\x1b[0m hello")
+	assert.Contains(t, errBuf.String(), "\x1b[33m WARN\x1b[0m careful")
+}
+
+func TestColor_CustomPaletteAndNever(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+	setter := log.(ColorSetter)
+
+	setter.SetColor(ColorAlways, Palette{ERROR: "\x1b[41m"})
+	_ = log.Error("bad")
+	log.Info("uncolored level")
+	assert.Contains(t, buf.String(), "\x1b[41mERROR\x1b[0m bad")
+	assert.Contains(t, buf.String(), " INFO uncolored level")
+
+	buf.Reset()
+	setter.SetColor(ColorNever)
+	_ = log.Error("bad")
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestColor_NoColorEnvDisablesAuto(t *testing.T) {
+	old, had := os.LookupEnv("NO_COLOR")
+	_ = os.Setenv("NO_COLOR", "1")
+	defer func() {
+		if had {
+			_ = os.Setenv("NO_COLOR", old)
+		} else {
+			_ = os.Unsetenv("NO_COLOR")
+		}
+	}()
+
+	assert.False(t, colorEnabled(ColorAuto, os.Stdout))
+	assert.True(t, colorEnabled(ColorAlways, os.Stdout))
+}
+
+func TestColor_RoutedOutputsAreNotColored(t *testing.T) {
+	var out, debugOut bytes.Buffer
+	log := NewWithWriters(&out, &out, DEBUG)
+	log.(ColorSetter).SetColor(ColorAlways)
+	log.(LevelRouter).SetOutputForLevel(DEBUG, &debugOut)
+
+	log.Debug("to file")
+
+	assert.Contains(t, debugOut.String(), "DEBUG to file")
+	assert.NotContains(t, debugOut.String(), "\x1b[")
+}
+
+func TestColor_PanicValueIsNotColored(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+	log.(ColorSetter).SetColor(ColorAlways)
+
+	var r interface{}
+	func() {
+		defer func() {
+			r = recover()
+		}()
+		log.Panic("boom")
+	}()
+
+	assert.Equal(t, "PANIC boom", r)
+	assert.Contains(t, buf.String(), "\x1b[1;31mPANIC\x1b[0m boom")
+}
+
+func TestSetColor_Default(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	assert.True(t, SetColor(ColorAlways))
+	Info("colored")
+
+	assert.Contains(t, out.String(), "\x1b[32m INFO\x1b[0m colored")
+}
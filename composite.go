@@ -1,5 +1,10 @@
 package glog
 
+import (
+	"fmt"
+	"time"
+)
+
 type compositeOuts struct {
 	chain []Output
 }
@@ -16,6 +21,11 @@ func (c compositeOuts) Printf(format string, a ...interface{}) {
 
 type composite struct {
 	chain []Logger
+	keys  *keyTable
+}
+
+func newComposite(chain []Logger) composite {
+	return composite{chain: chain, keys: newKeyTable(keyTableSize)}
 }
 
 func (c composite) Debug(format string, a ...interface{}) {
@@ -95,11 +105,36 @@ func (c composite) IsInfo() bool {
 }
 
 func (c composite) Error(format string, a ...interface{}) error {
-	var error error
+	fields, a := splitFields(a)
+	err := fmt.Errorf(format, a...)
+	c.logFields(ERROR, fields, "%s", err)
+	return err
+}
+
+func (c composite) ErrorErr(err error, format string, a ...interface{}) error {
+	wrapped := wrapError(err, format, a...)
+	c.logFields(ERROR, errorFields(err), "%s", wrapped)
+	return wrapped
+}
+
+func (c composite) logFields(logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	if logLevel == PANIC {
+		c.logRecord(&Record{Time: time.Now(), Level: PANIC, Message: fmt.Sprintf(format, a...), Fields: fields})
+		panic(message{level: logLevel, format: format, args: a, fields: fields}.String())
+	}
+	for _, l := range c.chain {
+		logWithFields(l, logLevel, fields, format, a...)
+	}
+}
+
+func (c composite) logRecord(r *Record) {
 	for _, l := range c.chain {
-		error = l.Error(format, a...)
+		logRecord(l, r)
 	}
-	return error
+}
+
+func (c composite) onceKeys() *keyTable {
+	return c.keys
 }
 
 func (c composite) IsError() bool {
@@ -117,6 +152,32 @@ func (c composite) Log(LogLevel LogLevel, format string, a ...interface{}) {
 	}
 }
 
+func (c composite) Logw(logLevel LogLevel, msg string, fields ...Field) {
+	format, args := messageFormat(msg)
+	c.logFields(logLevel, fields, format, args...)
+}
+
+func (c composite) Tracew(msg string, fields ...Field) {
+	c.Logw(TRACE, msg, fields...)
+}
+
+func (c composite) Debugw(msg string, fields ...Field) {
+	c.Logw(DEBUG, msg, fields...)
+}
+
+func (c composite) Infow(msg string, fields ...Field) {
+	c.Logw(INFO, msg, fields...)
+}
+
+func (c composite) Warnw(msg string, fields ...Field) {
+	c.Logw(WARN, msg, fields...)
+}
+
+func (c composite) Errorw(msg string, fields ...Field) error {
+	c.Logw(ERROR, msg, fields...)
+	return fieldsError(msg, fields)
+}
+
 func (c composite) IsEnabled(logLevel LogLevel) bool {
 	for _, l := range c.chain {
 		if l.IsEnabled(logLevel) {
@@ -134,10 +195,10 @@ func (c composite) GetOutput(LogLevel LogLevel) Output {
 	return compositeOutput(out...)
 }
 
+// Panic writes to every logger, then panics once.
 func (c composite) Panic(format string, a ...interface{}) {
-	for _, l := range c.chain {
-		l.Panic(format, a...)
-	}
+	fields, a := splitFields(a)
+	c.logFields(PANIC, fields, format, a...)
 }
 
 func (c composite) Fatal(format string, a ...interface{}) {
@@ -146,6 +207,25 @@ func (c composite) Fatal(format string, a ...interface{}) {
 	}
 }
 
+func (c composite) LogOnce(logLevel LogLevel, key string, format string, a ...interface{}) {
+	c.LogEvery(logLevel, key, 0, format, a...)
+}
+
+func (c composite) LogEvery(logLevel LogLevel, key string, every time.Duration, format string, a ...interface{}) {
+	if !c.IsEnabled(logLevel) || !c.keys.allow(key, every, time.Now()) {
+		return
+	}
+	c.Log(logLevel, format, a...)
+}
+
+func (c composite) WarnOnce(key string, format string, a ...interface{}) {
+	c.LogOnce(WARN, key, format, a...)
+}
+
+func (c composite) InfoEvery(key string, every time.Duration, format string, a ...interface{}) {
+	c.LogEvery(INFO, key, every, format, a...)
+}
+
 func (c composite) SetLevel(logLevel LogLevel) {
 	for _, l := range c.chain {
 		if setter, ok := l.(LevelSetter); ok {
@@ -154,6 +234,32 @@ func (c composite) SetLevel(logLevel LogLevel) {
 	}
 }
 
+func (c composite) SetColor(mode ColorMode, palette ...Palette) {
+	for _, l := range c.chain {
+		if setter, ok := l.(ColorSetter); ok {
+			setter.SetColor(mode, palette...)
+		}
+	}
+}
+
+func (c composite) SetErrorHandler(handler ErrorHandler) {
+	for _, l := range c.chain {
+		if setter, ok := l.(ErrorHandlerSetter); ok {
+			setter.SetErrorHandler(handler)
+		}
+	}
+}
+
+// LastWriteError returns the most recent write error of any of the loggers.
+func (c composite) LastWriteError() error {
+	err, _ := latestWriteError(c.chain...)
+	return err
+}
+
+func (c composite) lastWriteError() (error, time.Time) {
+	return latestWriteError(c.chain...)
+}
+
 // DefaultComposite sets the default logger to a composite that forwards every call to main and then to each of loggers.
 func DefaultComposite(main Logger, loggers ...Logger) {
 	setDefault(Composite(main, loggers...))
@@ -161,5 +267,5 @@ func DefaultComposite(main Logger, loggers ...Logger) {
 
 // Composite returns a Logger that forwards every log call to main and then to each of loggers (e.g. file + console).
 func Composite(main Logger, loggers ...Logger) Logger {
-	return composite{chain: append([]Logger{main}, loggers...)}
+	return newComposite(append([]Logger{main}, loggers...))
 }
@@ -33,6 +33,42 @@ func TestDefaultComposite_DefaultWritesToAll(t *testing.T) {
 	assert.Contains(t, buf2.String(), "multi")
 }
 
+func TestComposite_PanicWritesToAllThenPanicsOnce(t *testing.T) {
+	var first, second bytes.Buffer
+	log := Composite(NewWithWriters(&first, &first, INFO), NewWithWriters(&second, &second, INFO))
+
+	assert.PanicsWithValue(t, "PANIC stop 1", func() {
+		log.Panic("stop %d", 1)
+	})
+	assert.Contains(t, first.String(), "PANIC stop 1")
+	assert.Contains(t, second.String(), "PANIC stop 1")
+}
+
+// externalLogger stands for a Logger implemented outside this package: it has none of the unexported methods.
+type externalLogger struct {
+	Logger
+}
+
+func TestComposite_PanicReachesSinksAfterExternalLogger(t *testing.T) {
+	var first, second bytes.Buffer
+	log := Composite(externalLogger{NewWithWriters(&first, &first, INFO)}, NewWithWriters(&second, &second, INFO))
+
+	assert.PanicsWithValue(t, "PANIC boom", func() {
+		log.Panic("boom")
+	})
+	assert.Contains(t, first.String(), "PANIC boom")
+	assert.Contains(t, second.String(), "PANIC boom")
+
+	first.Reset()
+	second.Reset()
+	func() {
+		defer Recover(log)
+		panic("again")
+	}()
+	assert.Contains(t, first.String(), "PANIC recovered panic: again")
+	assert.Contains(t, second.String(), "PANIC recovered panic: again")
+}
+
 func TestComposite_IsEnabled(t *testing.T) {
 	debug := create(DEBUG)
 	warn := create(WARN)
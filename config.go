@@ -0,0 +1,561 @@
+package glog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a logger declaratively: a default level and a list of sinks. See Configure.
+type Config struct {
+	// Level is the minimum level for sinks that do not set their own; defaults to INFO.
+	Level string `json:"level" yaml:"level"`
+	// Sinks are combined with Composite; no sinks means a console sink at Level.
+	Sinks []SinkConfig `json:"sinks" yaml:"sinks"`
+}
+
+// SinkConfig describes one output of a configured logger.
+type SinkConfig struct {
+	// Type is one of "console" (stdout/stderr), "stdout", "stderr", "file" or "syslog".
+	Type string `json:"type" yaml:"type"`
+	// Level is the minimum level of this sink; defaults to Config.Level.
+	Level string `json:"level" yaml:"level"`
+	// Format is "text" (default), "logfmt" or "json".
+	Format string `json:"format" yaml:"format"`
+	// Path is the file to append to for "file" sinks.
+	Path string `json:"path" yaml:"path"`
+	// Color is "auto" (default), "always" or "never"; used by text console sinks.
+	Color string `json:"color" yaml:"color"`
+	// Network, Address and Tag configure "syslog" sinks; an empty Address means the local syslog daemon.
+	Network string `json:"network" yaml:"network"`
+	Address string `json:"address" yaml:"address"`
+	Tag     string `json:"tag" yaml:"tag"`
+	// Routes sends levels to dedicated targets ("stdout", "stderr" or a file path), as SetOutputs does.
+	Routes map[string]string `json:"routes" yaml:"routes"`
+	// OnError handles write failures: a comma-separated list of "retry" (3 attempts, 100ms apart), "stderr" (fail over)
+	// and "drop" (counted by ConfigDropped), tried in order. Empty ignores them.
+	OnError string `json:"on_error" yaml:"on_error"`
+	// Filters drop or keep this sink's records; see FilterConfig.
+	Filters []FilterConfig `json:"filters" yaml:"filters"`
+}
+
+// FilterConfig matches records meeting all of its set conditions.
+type FilterConfig struct {
+	// Action is "drop" (default) or "keep". If a sink has keep filters, it writes only records one of them matches.
+	Action string `json:"action" yaml:"action"`
+	// Levels lists level names, Message is a regular expression for the message and Logger a Named logger name
+	// (including its descendants); Fields maps field keys to values.
+	Levels  []string          `json:"levels" yaml:"levels"`
+	Message string            `json:"message" yaml:"message"`
+	Logger  string            `json:"logger" yaml:"logger"`
+	Fields  map[string]string `json:"fields" yaml:"fields"`
+}
+
+// Configure builds the logger described by cfg and installs it as Default(). On error the default is unchanged.
+// Files of the previously configured logger that cfg does not use again are closed.
+func Configure(cfg Config) error {
+	_, err := install(cfg)
+	return err
+}
+
+var (
+	configMu    sync.Mutex
+	configFiles = newFileSet()
+)
+
+// install builds cfg and makes it the default, handing over the files of the previous Configure or ConfigWatcher
+// install: paths used again keep their handles and the rest are closed. It returns the new file set.
+func install(cfg Config) (*fileSet, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	files := configFiles.next()
+	logger, err := cfg.build(files)
+	if err != nil {
+		files.rollback()
+		return nil, err
+	}
+	setDefault(logger)
+	files.commit()
+	configFiles = files
+	return files, nil
+}
+
+// ConfigureFile loads a JSON (.json) or YAML (.yaml, .yml) config file and installs it as Default().
+func ConfigureFile(path string) error {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return Configure(cfg)
+}
+
+// Build returns the Logger described by cfg without installing it.
+func (cfg Config) Build() (Logger, error) {
+	files := newFileSet()
+	logger, err := cfg.build(files)
+	if err != nil {
+		files.rollback()
+	}
+	return logger, err
+}
+
+func (cfg Config) build(files *fileSet) (Logger, error) {
+	level := INFO
+	if cfg.Level != "" {
+		parsed, err := ParseLevel(cfg.Level)
+		if err != nil {
+			return nil, err
+		}
+		level = parsed
+	}
+
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Type: "console"}}
+	}
+	var chain []Logger
+	for i, sink := range sinks {
+		logger, err := sink.build(level, files)
+		if err != nil {
+			return nil, fmt.Errorf("sink %d (%s): %w", i, sink.Type, err)
+		}
+		chain = append(chain, logger)
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return newComposite(chain), nil
+}
+
+func (sink SinkConfig) build(defaultLevel LogLevel, files *fileSet) (Logger, error) {
+	level := defaultLevel
+	if sink.Level != "" {
+		parsed, err := ParseLevel(sink.Level)
+		if err != nil {
+			return nil, err
+		}
+		level = parsed
+	}
+	enc, err := encoderFor(sink.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance logger
+	switch strings.ToLower(sink.Type) {
+	case "", "console":
+		if enc == nil {
+			instance = create(level)
+		} else {
+			instance = createWithWriters(Encoded(os.Stdout, enc), Encoded(os.Stderr, enc), level)
+		}
+		mode, err := parseColorMode(sink.Color)
+		if err != nil {
+			return nil, err
+		}
+		instance.SetColor(mode)
+	case "stdout", "stderr":
+		w := sinkWriter(sink.Type, nil, enc)
+		instance = createWithWriters(w, w, level)
+	case "file":
+		if sink.Path == "" {
+			return nil, fmt.Errorf("file sink requires a path")
+		}
+		f, err := files.open(sink.Path)
+		if err != nil {
+			return nil, err
+		}
+		w := sinkWriter("", f, enc)
+		instance = createWithWriters(w, w, level)
+	case "syslog":
+		w, err := newSyslogWriter(sink.Network, sink.Address, sink.Tag, enc)
+		if err != nil {
+			return nil, err
+		}
+		instance = createWithWriters(w, w, level)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", sink.Type)
+	}
+
+	if len(sink.Routes) > 0 {
+		outputs := make(map[LogLevel]io.Writer, len(sink.Routes))
+		for _, name := range sortedKeys(sink.Routes) {
+			target := sink.Routes[name]
+			routeLevel, err := ParseLevel(name)
+			if err != nil {
+				return nil, err
+			}
+			var f io.Writer
+			if target != "stdout" && target != "stderr" {
+				file, err := files.open(target)
+				if err != nil {
+					return nil, err
+				}
+				f = file
+			}
+			outputs[routeLevel] = sinkWriter(target, f, enc)
+		}
+		instance.SetOutputs(outputs)
+	}
+	if sink.OnError != "" {
+		handler, err := errorHandlerFor(sink.OnError)
+		if err != nil {
+			return nil, err
+		}
+		instance.SetErrorHandler(handler)
+	}
+	if len(sink.Filters) > 0 {
+		keep, err := filtersPredicate(sink.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return Filter(instance, keep), nil
+	}
+	return instance, nil
+}
+
+// errorHandlerFor parses SinkConfig.OnError.
+func errorHandlerFor(policies string) (ErrorHandler, error) {
+	var handlers []ErrorHandler
+	for _, policy := range strings.Split(policies, ",") {
+		switch strings.ToLower(strings.TrimSpace(policy)) {
+		case "retry":
+			handlers = append(handlers, Retry(3, 100*time.Millisecond))
+		case "stderr":
+			handlers = append(handlers, FailoverToStderr())
+		case "drop":
+			handlers = append(handlers, configDrops.Drop)
+		default:
+			return nil, fmt.Errorf("unknown write error policy %q", policy)
+		}
+	}
+	return Chain(handlers...), nil
+}
+
+// configDrops counts the lines dropped by every config sink with the "drop" write error policy.
+var configDrops DropCounter
+
+// ConfigDropped returns the number of lines dropped so far by config sinks with "on_error: drop".
+func ConfigDropped() uint64 {
+	return configDrops.Dropped()
+}
+
+// filtersPredicate combines filters: a record is kept if no drop filter matches and, when there are keep filters, one
+// of them matches.
+func filtersPredicate(filters []FilterConfig) (func(Record) bool, error) {
+	var drop, keep []func(Record) bool
+	for i, fc := range filters {
+		match, err := fc.predicate()
+		if err != nil {
+			return nil, fmt.Errorf("filter %d: %w", i, err)
+		}
+		switch strings.ToLower(fc.Action) {
+		case "", "drop":
+			drop = append(drop, match)
+		case "keep":
+			keep = append(keep, match)
+		default:
+			return nil, fmt.Errorf("filter %d: unknown action %q", i, fc.Action)
+		}
+	}
+	if len(keep) == 0 {
+		return Not(AnyOf(drop...)), nil
+	}
+	return AllOf(Not(AnyOf(drop...)), AnyOf(keep...)), nil
+}
+
+func (fc FilterConfig) predicate() (func(Record) bool, error) {
+	var conditions []func(Record) bool
+	if len(fc.Levels) > 0 {
+		levels := make([]LogLevel, len(fc.Levels))
+		for i, name := range fc.Levels {
+			level, err := ParseLevel(name)
+			if err != nil {
+				return nil, err
+			}
+			levels[i] = level
+		}
+		conditions = append(conditions, LevelIs(levels...))
+	}
+	if fc.Message != "" {
+		re, err := regexp.Compile(fc.Message)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, MessageMatches(re))
+	}
+	if fc.Logger != "" {
+		conditions = append(conditions, LoggerNamed(fc.Logger))
+	}
+	for _, key := range sortedKeys(fc.Fields) {
+		conditions = append(conditions, FieldEquals(key, fc.Fields[key]))
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("filter has no conditions")
+	}
+	return AllOf(conditions...), nil
+}
+
+// sinkWriter returns os.Stdout/os.Stderr for those names, otherwise w; wrapped with Encoded if enc is set.
+func sinkWriter(name string, w io.Writer, enc Encoder) io.Writer {
+	switch name {
+	case "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	}
+	if enc != nil {
+		return Encoded(w, enc)
+	}
+	return w
+}
+
+func encoderFor(format string) (Encoder, error) {
+	switch strings.ToLower(format) {
+	case "", "text":
+		return nil, nil
+	case "logfmt":
+		return LogfmtEncoder{}, nil
+	case "json":
+		return JSONEncoder{}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func parseColorMode(s string) (ColorMode, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return ColorAuto, nil
+	case "always", "on", "true":
+		return ColorAlways, nil
+	case "never", "off", "false":
+		return ColorNever, nil
+	}
+	return ColorAuto, fmt.Errorf("unknown color mode %q", s)
+}
+
+// fileSet opens each path once so sinks and routes sharing a file share its handle.
+// A set made with next takes over still-used handles from the previous one instead of reopening them.
+type fileSet struct {
+	files   map[string]*sinkFile
+	carried map[string]bool
+	prev    *fileSet
+}
+
+func newFileSet() *fileSet {
+	return &fileSet{files: make(map[string]*sinkFile), carried: make(map[string]bool)}
+}
+
+func (fs *fileSet) open(path string) (*sinkFile, error) {
+	key := filepath.Clean(path)
+	if f, ok := fs.files[key]; ok {
+		return f, nil
+	}
+	if fs.prev != nil {
+		if f, ok := fs.prev.files[key]; ok {
+			fs.files[key] = f
+			fs.carried[key] = true
+			return f, nil
+		}
+	}
+	f, err := openSinkFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fs.files[key] = f
+	return f, nil
+}
+
+// next returns an empty set that reuses this set's handles for paths it opens again.
+func (fs *fileSet) next() *fileSet {
+	n := newFileSet()
+	n.prev = fs
+	return n
+}
+
+// commit closes the previous set's handles that were not carried over.
+func (fs *fileSet) commit() {
+	if fs.prev == nil {
+		return
+	}
+	for key, f := range fs.prev.files {
+		if !fs.carried[key] {
+			_ = f.Close()
+		}
+	}
+	fs.prev = nil
+	fs.carried = make(map[string]bool)
+}
+
+// rollback closes the handles this set opened itself, leaving the previous set untouched.
+func (fs *fileSet) rollback() {
+	for key, f := range fs.files {
+		if !fs.carried[key] {
+			_ = f.Close()
+		}
+	}
+	fs.files = make(map[string]*sinkFile)
+	fs.carried = make(map[string]bool)
+}
+
+// sinkFile is a log file opened for a config sink. A logger still holding it after a reload closed it, e.g. one fetched
+// from Default() at startup, reopens the path on its next write; that handle is closed when the logger is collected.
+type sinkFile struct {
+	path string
+	mu   sync.Mutex
+	f    *os.File
+}
+
+func openSinkFile(path string) (*sinkFile, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &sinkFile{path: path, f: f}, nil
+}
+
+func (s *sinkFile) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return 0, err
+		}
+		s.f = f
+	}
+	return s.f.Write(p)
+}
+
+// Close closes the file; a later Write reopens it.
+func (s *sinkFile) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// LoadJSON parses a JSON Config.
+func LoadJSON(data []byte) (Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing JSON log config: %w", err)
+	}
+	return cfg, nil
+}
+
+// LoadYAML parses a YAML Config.
+func LoadYAML(data []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing YAML log config: %w", err)
+	}
+	return cfg, nil
+}
+
+// LoadConfigFile reads a JSON (.json) or YAML (.yaml, .yml) Config file.
+func LoadConfigFile(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return loadConfigData(path, data)
+}
+
+// loadConfigData parses data read from path as JSON or YAML, chosen by the path's extension.
+func loadConfigData(path string, data []byte) (Config, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSON(data)
+	case ".yaml", ".yml":
+		return LoadYAML(data)
+	}
+	return Config{}, fmt.Errorf("unknown log config file type %q", path)
+}
+
+// LoadEnv builds a Config from environment variables with the given prefix (default "GLOG_"):
+//
+//	GLOG_LEVEL=debug
+//	GLOG_SINKS=console,audit                 sink names; each name is also its default type
+//	GLOG_SINK_CONSOLE_LEVEL=info
+//	GLOG_SINK_AUDIT_TYPE=file
+//	GLOG_SINK_AUDIT_PATH=/var/log/audit.log
+//	GLOG_SINK_AUDIT_FORMAT=logfmt
+//	GLOG_SINK_AUDIT_ROUTES=error=/var/log/errors.log,debug=stdout
+//
+// Other sink keys are COLOR, NETWORK, ADDRESS, TAG and ON_ERROR.
+func LoadEnv(prefix ...string) (Config, error) {
+	p := "GLOG_"
+	if len(prefix) > 0 {
+		p = prefix[0]
+	}
+	cfg := Config{Level: os.Getenv(p + "LEVEL")}
+	for _, name := range strings.Split(os.Getenv(p+"SINKS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		key := p + "SINK_" + strings.ToUpper(name) + "_"
+		sink := SinkConfig{
+			Type:    os.Getenv(key + "TYPE"),
+			Level:   os.Getenv(key + "LEVEL"),
+			Format:  os.Getenv(key + "FORMAT"),
+			Path:    os.Getenv(key + "PATH"),
+			Color:   os.Getenv(key + "COLOR"),
+			Network: os.Getenv(key + "NETWORK"),
+			Address: os.Getenv(key + "ADDRESS"),
+			Tag:     os.Getenv(key + "TAG"),
+			OnError: os.Getenv(key + "ON_ERROR"),
+		}
+		if sink.Type == "" {
+			sink.Type = strings.ToLower(name)
+		}
+		routes, err := parseRoutes(os.Getenv(key + "ROUTES"))
+		if err != nil {
+			return Config{}, fmt.Errorf("%sROUTES: %w", key, err)
+		}
+		sink.Routes = routes
+		cfg.Sinks = append(cfg.Sinks, sink)
+	}
+	return cfg, nil
+}
+
+// parseRoutes parses "level=target,level=target".
+func parseRoutes(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	routes := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("invalid route %q, want level=target", pair)
+		}
+		routes[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return routes, nil
+}
+
+// sortedKeys returns the map keys in a stable order, for deterministic errors.
+func sortedKeys(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
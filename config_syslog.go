@@ -0,0 +1,79 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package glog
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"sync"
+	"time"
+)
+
+// newSyslogWriter returns an output sending each record to syslog at the severity of its level (see SyslogSeverity).
+// Syslog stamps the time itself, so the text format writes only the message and fields; enc, if set, encodes the
+// whole record instead.
+func newSyslogWriter(network, address, tag string, enc Encoder) (io.Writer, error) {
+	w, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogOutput{w: w, enc: enc}, nil
+}
+
+type syslogOutput struct {
+	mu  sync.Mutex
+	w   *syslog.Writer
+	enc Encoder
+}
+
+// Write sends p at INFO severity.
+func (o *syslogOutput) Write(p []byte) (int, error) {
+	return o.w.Write(p)
+}
+
+// Printf writes an INFO record; loggers call writeRecord with the real level.
+func (o *syslogOutput) Printf(format string, a ...interface{}) {
+	o.writeRecord(&Record{Time: time.Now(), Level: INFO, Message: fmt.Sprintf(format, a...)}, nil)
+}
+
+func (o *syslogOutput) writeRecord(r *Record, errs *writeErrors) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if o.enc != nil {
+		o.enc.Encode(buf, r)
+	} else {
+		buf.WriteString(r.Message)
+		writeFields(buf, "", r.Fields, nil)
+	}
+
+	writeLine(&o.mu, severityWriter(o.severityFunc(r.Level)), buf.Bytes(), errs)
+}
+
+// severityFunc returns the syslog.Writer method logging at logLevel's severity.
+func (o *syslogOutput) severityFunc(logLevel LogLevel) func(string) error {
+	switch SyslogSeverity(logLevel) {
+	case 7:
+		return o.w.Debug
+	case 4:
+		return o.w.Warning
+	case 3:
+		return o.w.Err
+	case 2:
+		return o.w.Crit
+	case 1:
+		return o.w.Alert
+	}
+	return o.w.Info
+}
+
+// severityWriter writes to syslog at one severity, so error handlers retry at the record's severity.
+type severityWriter func(string) error
+
+func (w severityWriter) Write(p []byte) (int, error) {
+	if err := w(string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
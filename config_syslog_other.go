@@ -0,0 +1,13 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package glog
+
+import (
+	"fmt"
+	"io"
+)
+
+func newSyslogWriter(network, address, tag string, enc Encoder) (io.Writer, error) {
+	return nil, fmt.Errorf("syslog sinks are not supported on this platform")
+}
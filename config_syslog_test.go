@@ -0,0 +1,41 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package glog
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure_SyslogSeverityByLevel(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	log, err := (Config{Level: "debug", Sinks: []SinkConfig{{
+		Type: "syslog", Network: "udp", Address: conn.LocalAddr().String(), Tag: "app",
+	}}}).Build()
+	require.NoError(t, err)
+
+	receive := func() string {
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	log.Debug("polling")
+	assert.Regexp(t, `^<15>.* app\[\d+\]: polling\n$`, receive())
+	log.Infow("started", Int("port", 80))
+	assert.Regexp(t, `^<14>.* app\[\d+\]: started port=80\n$`, receive())
+	log.Warn("slow")
+	assert.Regexp(t, `^<12>.* app\[\d+\]: slow\n$`, receive())
+	_ = log.Error("failed")
+	assert.Regexp(t, `^<11>.* app\[\d+\]: failed\n$`, receive())
+}
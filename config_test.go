@@ -0,0 +1,234 @@
+package glog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadJSON(t *testing.T) {
+	cfg, err := LoadJSON([]byte(`{
+		"level": "debug",
+		"sinks": [
+			{"type": "console", "level": "info", "color": "never"},
+			{"type": "file", "path": "/tmp/app.log", "format": "logfmt", "routes": {"error": "/tmp/errors.log"}}
+		]
+	}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Len(t, cfg.Sinks, 2)
+	assert.Equal(t, "never", cfg.Sinks[0].Color)
+	assert.Equal(t, "logfmt", cfg.Sinks[1].Format)
+	assert.Equal(t, map[string]string{"error": "/tmp/errors.log"}, cfg.Sinks[1].Routes)
+}
+
+func TestLoadYAML(t *testing.T) {
+	cfg, err := LoadYAML([]byte(`
+level: warn
+sinks:
+  - type: stderr
+  - type: file
+    path: /tmp/app.log
+    level: debug
+    routes:
+      debug: stdout
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "warn", cfg.Level)
+	assert.Equal(t, "stderr", cfg.Sinks[0].Type)
+	assert.Equal(t, "debug", cfg.Sinks[1].Level)
+	assert.Equal(t, "stdout", cfg.Sinks[1].Routes["debug"])
+}
+
+func TestLoadEnv(t *testing.T) {
+	env := map[string]string{
+		"TEST_GLOG_LEVEL":              "debug",
+		"TEST_GLOG_SINKS":              "console, audit",
+		"TEST_GLOG_SINK_CONSOLE_COLOR": "always",
+		"TEST_GLOG_SINK_AUDIT_TYPE":    "file",
+		"TEST_GLOG_SINK_AUDIT_PATH":    "/tmp/audit.log",
+		"TEST_GLOG_SINK_AUDIT_ROUTES":  "error=/tmp/errors.log,debug=stdout",
+	}
+	for k, v := range env {
+		_ = os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range env {
+			_ = os.Unsetenv(k)
+		}
+	}()
+
+	cfg, err := LoadEnv("TEST_GLOG_")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Config{
+		Level: "debug",
+		Sinks: []SinkConfig{
+			{Type: "console", Color: "always"},
+			{Type: "file", Path: "/tmp/audit.log", Routes: map[string]string{"error": "/tmp/errors.log", "debug": "stdout"}},
+		},
+	}, cfg)
+}
+
+func TestLoadEnv_InvalidRoute(t *testing.T) {
+	_ = os.Setenv("TEST_GLOG_SINKS", "file")
+	_ = os.Setenv("TEST_GLOG_SINK_FILE_ROUTES", "error")
+	defer os.Unsetenv("TEST_GLOG_SINKS")
+	defer os.Unsetenv("TEST_GLOG_SINK_FILE_ROUTES")
+
+	_, err := LoadEnv("TEST_GLOG_")
+
+	assert.Error(t, err)
+}
+
+func TestConfig_BuildFileSinksAndRoutes(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.log")
+	errLog := filepath.Join(dir, "errors.log")
+	cfg := Config{
+		Level: "debug",
+		Sinks: []SinkConfig{
+			{Type: "file", Path: appLog, Format: "logfmt", Routes: map[string]string{"error": errLog}},
+			{Type: "file", Path: errLog, Level: "warn"},
+		},
+	}
+
+	log, err := cfg.Build()
+	assert.NoError(t, err)
+	log.Debug("debug msg")
+	_ = log.Error("error msg")
+
+	app, _ := ioutil.ReadFile(appLog)
+	errs, _ := ioutil.ReadFile(errLog)
+	assert.Contains(t, string(app), `level=debug msg="debug msg"`)
+	assert.NotContains(t, string(app), "error msg")
+	assert.Contains(t, string(errs), `level=error msg="error msg"`)
+	assert.Contains(t, string(errs), "ERROR error msg")
+	assert.NotContains(t, string(errs), "debug msg")
+}
+
+func TestConfig_BuildSinkFilters(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.log")
+	cfg, err := LoadYAML([]byte(`
+level: debug
+sinks:
+  - type: file
+    path: ` + appLog + `
+    filters:
+      - logger: vendor
+        levels: [warn]
+      - message: "^healthcheck"
+`))
+	assert.NoError(t, err)
+
+	log, err := cfg.Build()
+	assert.NoError(t, err)
+	vendor := Named(log, "vendor")
+	vendor.Warn("chatty warning")
+	_ = vendor.Error("vendor error")
+	log.Warn("own warning")
+	log.Info("healthcheck ok")
+
+	app, _ := ioutil.ReadFile(appLog)
+	assert.NotContains(t, string(app), "chatty warning")
+	assert.Contains(t, string(app), "ERROR vendor error logger=vendor")
+	assert.Contains(t, string(app), "WARN own warning")
+	assert.NotContains(t, string(app), "healthcheck")
+}
+
+func TestConfig_BuildErrors(t *testing.T) {
+	cases := []Config{
+		{Level: "loud"},
+		{Sinks: []SinkConfig{{Type: "pager"}}},
+		{Sinks: []SinkConfig{{Type: "file"}}},
+		{Sinks: []SinkConfig{{Type: "console", Format: "xml"}}},
+		{Sinks: []SinkConfig{{Type: "console", Color: "rainbow"}}},
+		{Sinks: []SinkConfig{{Type: "stdout", Routes: map[string]string{"loud": "stderr"}}}},
+		{Sinks: []SinkConfig{{Type: "stdout", OnError: "retry,page"}}},
+		{Sinks: []SinkConfig{{Type: "stdout", Filters: []FilterConfig{{}}}}},
+		{Sinks: []SinkConfig{{Type: "stdout", Filters: []FilterConfig{{Message: "("}}}}},
+		{Sinks: []SinkConfig{{Type: "stdout", Filters: []FilterConfig{{Action: "hide", Levels: []string{"warn"}}}}}},
+	}
+	for _, cfg := range cases {
+		_, err := cfg.Build()
+		assert.Error(t, err, "%+v", cfg)
+	}
+}
+
+func TestConfig_DropPolicyIsCounted(t *testing.T) {
+	log, err := Config{Sinks: []SinkConfig{{Type: "stdout", OnError: "drop"}}}.Build()
+	assert.NoError(t, err)
+	w := &failingWriter{failures: 2}
+	log.(LevelRouter).SetOutputForLevel(INFO, w)
+	before := ConfigDropped()
+
+	log.Info("lost")
+	log.Info("lost too")
+	log.Info("kept")
+
+	assert.Equal(t, before+2, ConfigDropped())
+	assert.Contains(t, w.buf.String(), "kept")
+}
+
+func TestConfigure_InstallsDefault(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "configured.log")
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	err := Configure(Config{Sinks: []SinkConfig{{Type: "file", Path: fpath, Level: "debug"}}})
+	assert.NoError(t, err)
+	Debug("configured")
+
+	data, _ := ioutil.ReadFile(fpath)
+	assert.Contains(t, string(data), "DEBUG configured")
+}
+
+func TestConfigure_ClosesFilesOfPreviousConfig(t *testing.T) {
+	dir := t.TempDir()
+	kept, dropped := filepath.Join(dir, "kept.log"), filepath.Join(dir, "dropped.log")
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	assert.NoError(t, Configure(Config{Sinks: []SinkConfig{{Type: "file", Path: kept}, {Type: "file", Path: dropped}}}))
+	keptFile, droppedFile := configFiles.files[filepath.Clean(kept)], configFiles.files[filepath.Clean(dropped)]
+
+	assert.NoError(t, Configure(Config{Sinks: []SinkConfig{{Type: "file", Path: kept}}}))
+	assert.Same(t, keptFile, configFiles.files[filepath.Clean(kept)])
+	assert.NotNil(t, keptFile.f)
+	assert.Nil(t, droppedFile.f, "file of the replaced config is closed")
+
+	assert.NoError(t, Configure(Config{Sinks: []SinkConfig{{Type: "console"}}}))
+	assert.Nil(t, keptFile.f)
+}
+
+func TestConfigure_ErrorLeavesDefaultUnchanged(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	err := Configure(Config{Sinks: []SinkConfig{{Type: "pager"}}})
+	assert.Error(t, err)
+	Info("still default")
+
+	assert.Contains(t, out.String(), "still default")
+}
+
+func TestConfigureFile_YAML(t *testing.T) {
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "out.log")
+	cfgPath := filepath.Join(dir, "log.yaml")
+	assert.NoError(t, ioutil.WriteFile(cfgPath, []byte("sinks:\n  - type: file\n    path: "+fpath+"\n"), 0644))
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	assert.NoError(t, ConfigureFile(cfgPath))
+	Info("from yaml")
+
+	data, _ := ioutil.ReadFile(fpath)
+	assert.Contains(t, string(data), "INFO from yaml")
+	assert.Error(t, ConfigureFile(filepath.Join(dir, "log.toml")))
+}
@@ -2,15 +2,28 @@ package glog
 
 import (
 	"io"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
-type defaultHolder struct{ Logger }
+// defaultHolder keeps the installed logger and the redaction and filter set by SetRedaction and SetFilter, so they wrap
+// every logger later installed by Configure, WatchConfig, SetWriters, ToFile and the like.
+type defaultHolder struct {
+	Logger
+	base      Logger
+	redaction *Redaction
+	keep      func(Record) bool
+}
 
-var defaultLogger atomic.Value
+var (
+	defaultLogger atomic.Value
+	defaultMu     sync.Mutex
+)
 
 func init() {
-	defaultLogger.Store(defaultHolder{Logger: create(INFO)})
+	logger := create(INFO)
+	defaultLogger.Store(defaultHolder{Logger: logger, base: logger})
 }
 
 // Default returns the global default logger (initial level INFO, stdout/stderr).
@@ -18,8 +31,29 @@ func Default() Logger {
 	return defaultLogger.Load().(defaultHolder).Logger
 }
 
+// defaultBase returns the default logger without its SetFilter and SetRedaction wrappers, for the LevelRouter functions.
+func defaultBase() Logger {
+	return defaultLogger.Load().(defaultHolder).base
+}
+
 func setDefault(logger Logger) {
-	defaultLogger.Store(defaultHolder{Logger: logger})
+	updateDefault(func(h *defaultHolder) { h.base = logger })
+}
+
+// updateDefault changes the holder under defaultMu and re-applies the filter and redaction to its base logger.
+func updateDefault(update func(h *defaultHolder)) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	h := defaultLogger.Load().(defaultHolder)
+	update(&h)
+	h.Logger = h.base
+	if h.keep != nil {
+		h.Logger = Filter(h.Logger, h.keep)
+	}
+	if h.redaction != nil {
+		h.Logger = Redact(h.Logger, *h.redaction)
+	}
+	defaultLogger.Store(h)
 }
 
 // SetLevel sets the minimum level of the default logger. If it does not implement LevelSetter, replaces the default with a new logger.
@@ -36,9 +70,36 @@ func SetWriters(out io.Writer, err io.Writer, logLevel LogLevel) {
 	setDefault(NewWithWriters(out, err, logLevel))
 }
 
+// SetColor sets console coloring on the default logger. Returns true only if the default is a ColorSetter.
+func SetColor(mode ColorMode, palette ...Palette) bool {
+	if setter, ok := Default().(ColorSetter); ok {
+		setter.SetColor(mode, palette...)
+		return true
+	}
+	return false
+}
+
+// SetErrorHandler sets the handler for write failures on the default logger. Returns true only if the default is an
+// ErrorHandlerSetter.
+func SetErrorHandler(handler ErrorHandler) bool {
+	if setter, ok := Default().(ErrorHandlerSetter); ok {
+		setter.SetErrorHandler(handler)
+		return true
+	}
+	return false
+}
+
+// LastWriteError returns the default logger's most recent write failure, or nil.
+func LastWriteError() error {
+	if setter, ok := Default().(ErrorHandlerSetter); ok {
+		return setter.LastWriteError()
+	}
+	return nil
+}
+
 // SetOutputForLevel sets a dedicated output for the given level on the default logger. Returns true only if the default is a LevelRouter.
 func SetOutputForLevel(logLevel LogLevel, out io.Writer) bool {
-	if router, ok := Default().(LevelRouter); ok {
+	if router, ok := defaultBase().(LevelRouter); ok {
 		router.SetOutputForLevel(logLevel, out)
 		return true
 	}
@@ -47,13 +108,39 @@ func SetOutputForLevel(logLevel LogLevel, out io.Writer) bool {
 
 // SetOutputs sets per-level outputs on the default logger. Returns true only if the default is a LevelRouter.
 func SetOutputs(outputs map[LogLevel]io.Writer) bool {
-	if router, ok := Default().(LevelRouter); ok {
+	if router, ok := defaultBase().(LevelRouter); ok {
 		router.SetOutputs(outputs)
 		return true
 	}
 	return false
 }
 
+// SetThresholdOutputs sets outputs with their own minimum level on the default logger. Returns true only if the default is a LevelRouter.
+func SetThresholdOutputs(outputs ...ThresholdOutput) bool {
+	if router, ok := defaultBase().(LevelRouter); ok {
+		router.SetThresholdOutputs(outputs...)
+		return true
+	}
+	return false
+}
+
+// AddOutputForLevel adds an output for the given level on the default logger, keeping existing ones.
+// The second result is false if the default is not a LevelRouter.
+func AddOutputForLevel(logLevel LogLevel, out io.Writer) (OutputHandle, bool) {
+	if router, ok := defaultBase().(LevelRouter); ok {
+		return router.AddOutputForLevel(logLevel, out), true
+	}
+	return OutputHandle{}, false
+}
+
+// RemoveOutput removes an output added to the default logger; it reports whether it was found.
+func RemoveOutput(handle OutputHandle) bool {
+	if router, ok := defaultBase().(LevelRouter); ok {
+		return router.RemoveOutput(handle)
+	}
+	return false
+}
+
 // Trace logs at TRACE level using the default logger.
 func Trace(format string, a ...interface{}) {
 	Default().Trace(format, a...)
@@ -99,6 +186,11 @@ func Error(format string, a ...interface{}) error {
 	return Default().Error(format, a...)
 }
 
+// ErrorErr logs at ERROR level using the default logger and returns an error wrapping err (see ErrorLogger).
+func ErrorErr(err error, format string, a ...interface{}) error {
+	return Default().ErrorErr(err, format, a...)
+}
+
 // IsError reports whether the default logger is enabled for ERROR.
 func IsError() bool {
 	return Default().IsError()
@@ -119,11 +211,61 @@ func Fatal(format string, a ...interface{}) {
 	Default().Fatal(format, a...)
 }
 
+// LogOnce logs at the given level using the default logger, only the first time key is seen.
+func LogOnce(level LogLevel, key string, format string, a ...interface{}) {
+	Default().LogOnce(level, key, format, a...)
+}
+
+// LogEvery logs at the given level using the default logger, at most once per every for key.
+func LogEvery(level LogLevel, key string, every time.Duration, format string, a ...interface{}) {
+	Default().LogEvery(level, key, every, format, a...)
+}
+
+// WarnOnce logs at WARN level using the default logger, only the first time key is seen.
+func WarnOnce(key string, format string, a ...interface{}) {
+	Default().WarnOnce(key, format, a...)
+}
+
+// InfoEvery logs at INFO level using the default logger, at most once per every for key.
+func InfoEvery(key string, every time.Duration, format string, a ...interface{}) {
+	Default().InfoEvery(key, every, format, a...)
+}
+
 // Log writes to the default logger at the given level with the format and args.
 func Log(level LogLevel, a string, objs ...interface{}) {
 	Default().Log(level, a, objs...)
 }
 
+// Logw writes msg with fields to the default logger at the given level.
+func Logw(level LogLevel, msg string, fields ...Field) {
+	Default().Logw(level, msg, fields...)
+}
+
+// Tracew logs msg with fields at TRACE level using the default logger.
+func Tracew(msg string, fields ...Field) {
+	Default().Tracew(msg, fields...)
+}
+
+// Debugw logs msg with fields at DEBUG level using the default logger.
+func Debugw(msg string, fields ...Field) {
+	Default().Debugw(msg, fields...)
+}
+
+// Infow logs msg with fields at INFO level using the default logger.
+func Infow(msg string, fields ...Field) {
+	Default().Infow(msg, fields...)
+}
+
+// Warnw logs msg with fields at WARN level using the default logger.
+func Warnw(msg string, fields ...Field) {
+	Default().Warnw(msg, fields...)
+}
+
+// Errorw logs msg with fields at ERROR level using the default logger and returns an error (see StructuredLogger).
+func Errorw(msg string, fields ...Field) error {
+	return Default().Errorw(msg, fields...)
+}
+
 // OutputLevel returns an Output that writes at the given level to the default logger.
 func OutputLevel(level LogLevel) Output {
 	return Default().GetOutput(level)
@@ -131,7 +273,12 @@ func OutputLevel(level LogLevel) Output {
 
 // ToFile switches the default logger to append to the given file. Level defaults to INFO. On open failure, the default logger is unchanged.
 func ToFile(file string, level ...LogLevel) {
-	log, error := createFileLogger(file, level...)
+	ToFileEncoded(file, nil, level...)
+}
+
+// ToFileEncoded is ToFile with records encoded by enc (e.g. LogfmtEncoder{}); a nil enc means the default text format.
+func ToFileEncoded(file string, enc Encoder, level ...LogLevel) {
+	log, error := createFileLogger(file, enc, level...)
 	if error == nil {
 		setDefault(log)
 	}
@@ -139,12 +286,10 @@ func ToFile(file string, level ...LogLevel) {
 
 // ToFileAndConsole sets the default logger to a composite: file (at fileLevel) and console (at consoleLevel). On file open failure, logs the error and leaves the default unchanged.
 func ToFileAndConsole(file string, fileLevel LogLevel, consoleLevel LogLevel) {
-	log, err := createFileLogger(file, fileLevel)
+	log, err := createFileLogger(file, nil, fileLevel)
 	if err != nil {
 		_ = Error("Can't create file logger for composite logger: %v", err)
 		return
 	}
-	setDefault(composite{
-		chain: []Logger{log, create(consoleLevel)},
-	})
+	setDefault(newComposite([]Logger{log, create(consoleLevel)}))
 }
@@ -75,6 +75,33 @@ func TestSetOutputs_RoutesByLevel(t *testing.T) {
 	assert.Contains(t, errBuf.String(), "warn only")
 }
 
+func TestSetThresholdOutputs_Default(t *testing.T) {
+	var out, errBuf, file bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	assert.True(t, SetThresholdOutputs(ThresholdOutput{MinLevel: INFO, Writer: &file}))
+	Info("to file")
+
+	assert.Contains(t, file.String(), "to file")
+	assert.Empty(t, out.String())
+}
+
+func TestAddOutputForLevel_Default(t *testing.T) {
+	var out, errBuf, alerts bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	handle, ok := AddOutputForLevel(ERROR, &alerts)
+	assert.True(t, ok)
+	_ = Error("alert")
+	assert.Contains(t, alerts.String(), "ERROR alert")
+
+	assert.True(t, RemoveOutput(handle))
+	_ = Error("back")
+	assert.Contains(t, errBuf.String(), "ERROR back")
+}
+
 func TestToFile_WritesToFile(t *testing.T) {
 	fpath := filepath.Join(os.TempDir(), "glog_test_tofile.txt")
 	defer func() {
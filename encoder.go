@@ -0,0 +1,161 @@
+package glog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a single log event as passed to an Encoder.
+type Record struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+	Fields  []Field
+}
+
+// message is a log call before it is written: as a Record for encoders, or as a format for text outputs.
+type message struct {
+	level  LogLevel
+	format string
+	args   []interface{}
+	fields []Field
+	at     time.Time // zero means now
+}
+
+// text returns the format and arguments for a text output, with prefix first and fields appended.
+func (m message) text(prefix string) (string, []interface{}) {
+	if len(m.fields) == 0 {
+		return prefix + " " + m.format, m.args
+	}
+	return prefix + " %s", []interface{}{fmt.Sprintf(m.format, m.args...) + formatFields(m.fields)}
+}
+
+// String returns the uncolored text line, used as the panic value.
+func (m message) String() string {
+	format, args := m.text(m.level.prefix)
+	return fmt.Sprintf(format, args...)
+}
+
+func (m message) time() time.Time {
+	if m.at.IsZero() {
+		return time.Now()
+	}
+	return m.at
+}
+
+func (m message) record() *Record {
+	return &Record{
+		Time:    m.time(),
+		Level:   m.level,
+		Message: fmt.Sprintf(m.format, m.args...),
+		Fields:  m.fields,
+	}
+}
+
+// Encoder formats a Record as one line (including the trailing newline) into buf.
+type Encoder interface {
+	Encode(buf *bytes.Buffer, r *Record)
+}
+
+// TextEncoder writes the default format: "2006/01/02 15:04:05 LEVEL message key=value".
+type TextEncoder struct{}
+
+// Encode implements Encoder.
+func (TextEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	buf.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+	buf.WriteString(r.Level.prefix)
+	buf.WriteByte(' ')
+	buf.WriteString(r.Message)
+	writeFields(buf, "", r.Fields, nil)
+	if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+}
+
+// Encoded returns a writer that makes loggers encode records to w with enc instead of the default text format.
+// Use it wherever a writer is accepted: NewWithWriters, NewLevelRouter, SetOutputForLevel, SetOutputs, SetWriters.
+// Writing to the returned writer directly passes bytes through to w.
+func Encoded(w io.Writer, enc Encoder) io.Writer {
+	if enc == nil {
+		enc = TextEncoder{}
+	}
+	return &encodedWriter{w: w, enc: enc}
+}
+
+type encodedWriter struct {
+	w   io.Writer
+	enc Encoder
+}
+
+func (e *encodedWriter) Write(p []byte) (int, error) {
+	return e.w.Write(p)
+}
+
+func (e *encodedWriter) encoding() (io.Writer, Encoder) {
+	return e.w, e.enc
+}
+
+// encodingWriter is a writer that has loggers encode records for it, like those made by Encoded.
+type encodingWriter interface {
+	io.Writer
+	encoding() (io.Writer, Encoder)
+}
+
+// recordOutput is an Output that encodes whole records rather than preformatted lines.
+type recordOutput interface {
+	Output
+	writeRecord(r *Record, errs *writeErrors)
+}
+
+type encodedOutput struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc Encoder
+}
+
+func newEncodedOutput(w io.Writer, enc Encoder) *encodedOutput {
+	return &encodedOutput{w: w, enc: enc}
+}
+
+func (o *encodedOutput) writeRecord(r *Record, errs *writeErrors) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	o.enc.Encode(buf, r)
+
+	writeLine(&o.mu, o.w, buf.Bytes(), errs)
+}
+
+// Printf writes an INFO record; loggers call writeRecord with the real level.
+func (o *encodedOutput) Printf(format string, a ...interface{}) {
+	o.writeRecord(&Record{Time: time.Now(), Level: INFO, Message: fmt.Sprintf(format, a...)}, nil)
+}
+
+// levelName returns the lower-case level name without padding (e.g. "info").
+func levelName(logLevel LogLevel) string {
+	return strings.ToLower(strings.TrimSpace(logLevel.prefix))
+}
+
+// maxPooledBuffer keeps unusually large buffers out of the pool.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,85 @@
+package glog
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextEncoder_MatchesDefaultFormat(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Record{
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   WARN,
+		Message: "disk almost full",
+		Fields:  []Field{{Key: "free", Value: "1 GB"}},
+	}
+
+	TextEncoder{}.Encode(&buf, r)
+
+	assert.Equal(t, "2026/01/02 03:04:05  WARN disk almost full free=\"1 GB\"\n", buf.String())
+}
+
+func TestEncoded_NewWithWriters(t *testing.T) {
+	var out, err bytes.Buffer
+	log := NewWithWriters(Encoded(&out, LogfmtEncoder{}), Encoded(&err, LogfmtEncoder{}), INFO)
+
+	log.Info("hello %s", "world")
+	log.Warn("careful")
+
+	assert.Contains(t, out.String(), `level=info msg="hello world"`)
+	assert.Contains(t, err.String(), "level=warn msg=careful")
+}
+
+func TestEncoded_LevelRouterOutputs(t *testing.T) {
+	var debugOut bytes.Buffer
+	router := NewLevelRouter(map[LogLevel]io.Writer{
+		DEBUG: Encoded(&debugOut, LogfmtEncoder{}),
+	}, DEBUG)
+
+	router.Debug("routed")
+
+	assert.Contains(t, debugOut.String(), "level=debug msg=routed")
+}
+
+func TestEncoded_FieldsAreEncodedSeparately(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(Encoded(&buf, LogfmtEncoder{}), Encoded(&buf, LogfmtEncoder{}), INFO)
+
+	_ = log.ErrorErr(io.EOF, "read failed")
+
+	assert.Contains(t, buf.String(), `level=error msg="read failed: EOF" error_type=*errors.errorString`)
+}
+
+func TestEncoded_PanicWritesRecordAndPanics(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(Encoded(&buf, LogfmtEncoder{}), Encoded(&buf, LogfmtEncoder{}), INFO)
+
+	var r interface{}
+	func() {
+		defer func() {
+			r = recover()
+		}()
+		log.Panic("boom")
+	}()
+
+	assert.Equal(t, "PANIC boom", r)
+	assert.Contains(t, buf.String(), "level=panic msg=boom")
+}
+
+func TestEncoded_FatalWritesRecordAndExits(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(Encoded(&buf, LogfmtEncoder{}), Encoded(&buf, LogfmtEncoder{}), INFO)
+	code := -1
+	exit := osExit
+	osExit = func(c int) { code = c }
+	defer func() { osExit = exit }()
+
+	log.Fatal("bye")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, buf.String(), "level=fatal msg=bye")
+}
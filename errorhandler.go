@@ -0,0 +1,230 @@
+package glog
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorHandler deals with a failed write to a sink: w writes to the sink's writer, p is the part of the line not
+// written and err the write error. It returns nil once the line is taken care of (written on retry or elsewhere, or
+// deliberately dropped), otherwise the error that remains. Handlers run without holding the sink's lock, so other
+// goroutines keep logging meanwhile.
+type ErrorHandler func(w io.Writer, p []byte, err error) error
+
+// ErrorHandlerSetter is a Logger whose write failures can be handled and inspected. Without a handler failures are
+// ignored as before, but still recorded for LastWriteError.
+type ErrorHandlerSetter interface {
+	SetErrorHandler(handler ErrorHandler)
+	LastWriteError() error
+}
+
+// Retry writes the line again up to attempts times, waiting delay before each attempt.
+func Retry(attempts int, delay time.Duration) ErrorHandler {
+	return func(w io.Writer, p []byte, err error) error {
+		for i := 0; i < attempts; i++ {
+			time.Sleep(delay)
+			n, retryErr := w.Write(p)
+			if retryErr == nil {
+				return nil
+			}
+			p, err = p[n:], retryErr
+		}
+		return err
+	}
+}
+
+// Failover writes the line to fallback instead.
+func Failover(fallback io.Writer) ErrorHandler {
+	return func(_ io.Writer, p []byte, _ error) error {
+		_, err := fallback.Write(p)
+		return err
+	}
+}
+
+// FailoverToStderr writes the line to os.Stderr instead.
+func FailoverToStderr() ErrorHandler {
+	return func(w io.Writer, p []byte, err error) error {
+		return Failover(os.Stderr)(w, p, err)
+	}
+}
+
+// DropCounter drops failed lines and counts them; use its Drop method as an ErrorHandler.
+type DropCounter struct {
+	dropped uint64
+}
+
+// Drop is an ErrorHandler that discards the line.
+func (c *DropCounter) Drop(_ io.Writer, _ []byte, _ error) error {
+	atomic.AddUint64(&c.dropped, 1)
+	return nil
+}
+
+// Dropped returns the number of lines dropped so far.
+func (c *DropCounter) Dropped() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
+// Chain tries handlers in order until one returns nil, e.g. Chain(Retry(3, 10*time.Millisecond), FailoverToStderr()).
+func Chain(handlers ...ErrorHandler) ErrorHandler {
+	return func(w io.Writer, p []byte, err error) error {
+		for _, handler := range handlers {
+			if err = handler(w, p, err); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+}
+
+// WithErrorHandler returns a writer for a single output (e.g. one passed to SetOutputForLevel) that handles its own
+// write failures. The logger still records them for LastWriteError, but only passes on the ones handler could not deal
+// with. Writers made with Encoded keep encoding.
+func WithErrorHandler(w io.Writer, handler ErrorHandler) io.Writer {
+	if encoded, ok := w.(encodingWriter); ok {
+		inner, enc := encoded.encoding()
+		return Encoded(WithErrorHandler(inner, handler), enc)
+	}
+	return &handledWriter{w: w, handler: handler}
+}
+
+type handledWriter struct {
+	w       io.Writer
+	handler ErrorHandler
+}
+
+func (h *handledWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	if err == nil {
+		return n, nil
+	}
+	if handlerErr := h.handler(h.w, p[n:], err); handlerErr != nil {
+		return n, handlerErr
+	}
+	return len(p), handledError{err: err}
+}
+
+// writeLine writes p to w holding mu. A failure goes to the error handlers (w's own, if made with WithErrorHandler, then
+// errs) after mu is released, so a slow handler such as Retry does not hold up other goroutines logging to w; their
+// writes to w take mu again.
+func writeLine(mu *sync.Mutex, w io.Writer, p []byte, errs *writeErrors) {
+	var own ErrorHandler
+	if handled, ok := w.(*handledWriter); ok {
+		w, own = handled.w, handled.handler
+	}
+	mu.Lock()
+	n, err := w.Write(p)
+	mu.Unlock()
+	if err == nil {
+		return
+	}
+
+	locked := &lockedWriter{mu: mu, w: w}
+	rest := p[n:]
+	if own != nil {
+		if handlerErr := own(locked, rest, err); handlerErr != nil {
+			err = handlerErr
+		} else {
+			err = handledError{err: err}
+		}
+	}
+	errs.report(locked, rest, err)
+}
+
+// lockedWriter writes to w holding mu, for error handlers writing to an output's writer.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// handledError is a write error an output's own handler has dealt with.
+type handledError struct {
+	err error
+}
+
+func (e handledError) Error() string {
+	return e.err.Error()
+}
+
+func (e handledError) Unwrap() error {
+	return e.err
+}
+
+// writeErrors holds a logger's ErrorHandler and its last write error; copies of the logger share it.
+type writeErrors struct {
+	mu      sync.Mutex
+	handler ErrorHandler
+	last    error
+	at      time.Time
+}
+
+func newWriteErrors() *writeErrors {
+	return &writeErrors{}
+}
+
+// report records a failed write of p to w and passes it to the handler unless an output's own handler dealt with it.
+func (e *writeErrors) report(w io.Writer, p []byte, err error) {
+	if e == nil {
+		return
+	}
+	var handled handledError
+	wasHandled := errors.As(err, &handled)
+	if wasHandled {
+		err = handled.err
+	}
+
+	e.mu.Lock()
+	e.last, e.at = err, time.Now()
+	handler := e.handler
+	e.mu.Unlock()
+
+	if handler != nil && !wasHandled {
+		_ = handler(w, p, err)
+	}
+}
+
+func (e *writeErrors) setHandler(handler ErrorHandler) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handler = handler
+}
+
+func (e *writeErrors) lastError() (error, time.Time) {
+	if e == nil {
+		return nil, time.Time{}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.last, e.at
+}
+
+// lastWriteErrorReporter lets a Composite pick the most recent error of its loggers.
+type lastWriteErrorReporter interface {
+	lastWriteError() (error, time.Time)
+}
+
+// latestWriteError returns the most recent write error among loggers.
+func latestWriteError(loggers ...Logger) (error, time.Time) {
+	var latest error
+	var at time.Time
+	for _, l := range loggers {
+		if reporter, ok := l.(lastWriteErrorReporter); ok {
+			if err, when := reporter.lastWriteError(); err != nil && when.After(at) {
+				latest, at = err, when
+			}
+		}
+	}
+	return latest, at
+}
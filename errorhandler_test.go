@@ -0,0 +1,144 @@
+package glog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errDiskFull = errors.New("disk full")
+
+// failingWriter fails its first failures writes, then writes to buf.
+type failingWriter struct {
+	failures int
+	buf      bytes.Buffer
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.failures > 0 {
+		w.failures--
+		return 0, errDiskFull
+	}
+	return w.buf.Write(p)
+}
+
+func TestErrorHandler_RecordsLastWriteError(t *testing.T) {
+	w := &failingWriter{failures: 1}
+	log := NewWithWriters(w, w, INFO)
+	setter := log.(ErrorHandlerSetter)
+
+	assert.NoError(t, setter.LastWriteError())
+	log.Info("lost")
+	assert.Equal(t, errDiskFull, setter.LastWriteError())
+	log.Info("written")
+	assert.Contains(t, w.buf.String(), "INFO written")
+}
+
+func TestErrorHandler_RetryThenFailover(t *testing.T) {
+	var fallback bytes.Buffer
+	retried := &failingWriter{failures: 1}
+	log := NewWithWriters(retried, retried, INFO)
+	log.(ErrorHandlerSetter).SetErrorHandler(Chain(Retry(1, 0), Failover(&fallback)))
+
+	log.Info("first")
+	assert.Contains(t, retried.buf.String(), "INFO first")
+	assert.Empty(t, fallback.String())
+
+	retried.failures = 2
+	log.Info("second")
+	assert.NotContains(t, retried.buf.String(), "second")
+	assert.Contains(t, fallback.String(), "INFO second")
+}
+
+func TestErrorHandler_RunsWithoutHoldingTheOutput(t *testing.T) {
+	w := &failingWriter{failures: 1}
+	log := NewWithWriters(w, w, INFO)
+	handling, release, handled := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	log.(ErrorHandlerSetter).SetErrorHandler(func(w io.Writer, p []byte, _ error) error {
+		close(handling)
+		<-release
+		_, err := w.Write(p)
+		close(handled)
+		return err
+	})
+
+	go log.Info("first")
+	<-handling
+	logged := make(chan struct{})
+	go func() {
+		log.Info("second")
+		close(logged)
+	}()
+	select {
+	case <-logged:
+	case <-time.After(time.Second):
+		t.Fatal("logging waited for another goroutine's error handler")
+	}
+	close(release)
+	<-handled
+	assert.Contains(t, w.buf.String(), "INFO second")
+	assert.Contains(t, w.buf.String(), "INFO first")
+}
+
+func TestErrorHandler_DropCounter(t *testing.T) {
+	var counter DropCounter
+	w := &failingWriter{failures: 2}
+	log := NewWithWriters(Encoded(w, LogfmtEncoder{}), w, INFO)
+	log.(ErrorHandlerSetter).SetErrorHandler(counter.Drop)
+
+	log.Info("a")
+	log.Info("b")
+	log.Info("c")
+
+	assert.Equal(t, uint64(2), counter.Dropped())
+	assert.Contains(t, w.buf.String(), "msg=c")
+}
+
+func TestWithErrorHandler_PerRoutedOutput(t *testing.T) {
+	var console, alerts bytes.Buffer
+	var loggerHandled int
+	log := NewWithWriters(&console, &console, INFO)
+	router := log.(LevelRouter)
+	router.(ErrorHandlerSetter).SetErrorHandler(func(io.Writer, []byte, error) error {
+		loggerHandled++
+		return nil
+	})
+	broken := &failingWriter{failures: 1}
+	router.AddOutputForLevel(ERROR, WithErrorHandler(broken, Failover(&alerts)))
+
+	_ = log.Error("boom")
+
+	assert.Contains(t, alerts.String(), "ERROR boom")
+	assert.Equal(t, 0, loggerHandled)
+	assert.Equal(t, errDiskFull, router.(ErrorHandlerSetter).LastWriteError())
+}
+
+func TestLastWriteError_CompositeAndDefault(t *testing.T) {
+	ok := &bytes.Buffer{}
+	broken := &failingWriter{failures: 1}
+	SetWriters(ok, ok, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+	DefaultComposite(Default(), Named(NewWithWriters(broken, broken, INFO), "file"))
+
+	var handled []string
+	assert.True(t, SetErrorHandler(func(_ io.Writer, p []byte, _ error) error {
+		handled = append(handled, string(p))
+		return nil
+	}))
+	Info("hello")
+
+	assert.Equal(t, errDiskFull, LastWriteError())
+	assert.Len(t, handled, 1)
+	assert.Contains(t, handled[0], "INFO hello logger=file")
+}
+
+func TestRetry_GivesUpAfterAttempts(t *testing.T) {
+	w := &failingWriter{failures: 3}
+	err := Retry(2, time.Millisecond)(w, []byte("x"), errDiskFull)
+	assert.Equal(t, errDiskFull, err)
+}
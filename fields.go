@@ -0,0 +1,160 @@
+package glog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Field is a key/value pair attached to a log message and written after it as key=value. Fields made with the typed
+// constructors (String, Int, Duration, ...) keep their value unboxed, so Value is nil for them; use Interface to read it.
+type Field struct {
+	Key   string
+	Value interface{}
+
+	kind fieldKind
+	num  int64
+	str  string
+	loc  *time.Location
+}
+
+// fieldLogger is implemented by loggers that can write fields alongside a message.
+type fieldLogger interface {
+	logFields(logLevel LogLevel, fields []Field, format string, a ...interface{})
+}
+
+// recordLogger is implemented by loggers that can write a record whatever their level, keeping its time, so a
+// FlightRecorder can write out records held back below the sink's level.
+type recordLogger interface {
+	logRecord(r *Record)
+}
+
+// logRecord writes r to l past its level check; other Logger implementations log it as usual. A PANIC record never
+// panics: the panic of another implementation's Log is swallowed, so a Composite still reaches the loggers after it.
+func logRecord(l Logger, r *Record) {
+	if rl, ok := l.(recordLogger); ok {
+		rl.logRecord(r)
+		return
+	}
+	if r.Level == PANIC {
+		defer func() {
+			_ = recover()
+		}()
+	}
+	logWithFields(l, r.Level, r.Fields, "%s", r.Message)
+}
+
+func logWithFields(l Logger, logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	if fl, ok := l.(fieldLogger); ok {
+		fl.logFields(logLevel, fields, format, a...)
+		return
+	}
+	l.Log(logLevel, format+strings.ReplaceAll(formatFields(fields), "%", "%%"), a...)
+}
+
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	writeFields(&buf, "", fields, nil)
+	return buf.String()
+}
+
+// writeFields writes fields as " key=value", with keys passed through key if set. Object fields are flattened into
+// " key.name=value".
+func writeFields(buf *bytes.Buffer, prefix string, fields []Field, key func(string) string) {
+	for _, f := range fields {
+		if f.kind == objectField {
+			writeFields(buf, prefix+f.Key+".", f.objectFields(), key)
+			continue
+		}
+		name := f.Key
+		if prefix != "" {
+			name = prefix + name
+		}
+		if key != nil {
+			name = key(name)
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		writeFieldText(buf, f)
+	}
+}
+
+// writeFieldText writes the value of f for key=value output, quoted if needed.
+func writeFieldText(buf *bytes.Buffer, f Field) {
+	var scratch [40]byte
+	switch f.kind {
+	case stringField:
+		writeQuotedValue(buf, f.str)
+	case intField:
+		buf.Write(strconv.AppendInt(scratch[:0], f.num, 10))
+	case boolField:
+		buf.Write(strconv.AppendBool(scratch[:0], f.num != 0))
+	case floatField:
+		buf.Write(strconv.AppendFloat(scratch[:0], f.float(), 'g', -1, 64))
+	case timeField:
+		buf.Write(f.time().AppendFormat(scratch[:0], time.RFC3339Nano))
+	case plainField:
+		writeQuotedValue(buf, fmt.Sprint(f.Value))
+	default:
+		writeQuotedValue(buf, f.text())
+	}
+}
+
+func writeQuotedValue(buf *bytes.Buffer, s string) {
+	if needsQuoting(s) {
+		buf.WriteString(strconv.Quote(s))
+		return
+	}
+	buf.WriteString(s)
+}
+
+func quoteFieldValue(s string) string {
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == '"' || r == '=' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapError returns format/a as an error wrapping err with %w, so errors.Is and errors.As see err.
+func wrapError(err error, format string, a ...interface{}) error {
+	if err == nil {
+		return fmt.Errorf(format, a...)
+	}
+	args := append(append(make([]interface{}, 0, len(a)+1), a...), err)
+	return fmt.Errorf(format+": %w", args...)
+}
+
+// errorFields describes err as fields: its type and the types along its Unwrap chain.
+func errorFields(err error) []Field {
+	if err == nil {
+		return nil
+	}
+	var chain []string
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		chain = append(chain, fmt.Sprintf("%T", e))
+	}
+	return []Field{
+		{Key: "error_type", Value: chain[0]},
+		{Key: "error_chain", Value: strings.Join(chain, " > ")},
+	}
+}
@@ -0,0 +1,61 @@
+package glog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorErr_WrapsAndLogsFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	_, cause := os.Open("/nonexistent/glog/config.yaml")
+	err := log.ErrorErr(cause, "loading config %s", "app")
+
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	var pathErr *os.PathError
+	assert.True(t, errors.As(err, &pathErr))
+	assert.Contains(t, err.Error(), "loading config app: open /nonexistent/glog/config.yaml")
+	assert.Contains(t, buf.String(), "ERROR loading config app: open")
+	assert.Contains(t, buf.String(), "error_type=*fs.PathError")
+	assert.Contains(t, buf.String(), `error_chain="*fs.PathError > syscall.Errno"`)
+}
+
+func TestErrorErr_NilErr(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	err := log.ErrorErr(nil, "plain %d", 1)
+
+	assert.EqualError(t, err, "plain 1")
+	assert.Contains(t, buf.String(), "ERROR plain 1")
+	assert.NotContains(t, buf.String(), "error_type")
+}
+
+func TestComposite_ErrorReturnsSingleError(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	comp := Composite(NewWithWriters(&buf1, &buf1, INFO), NewWithWriters(&buf2, &buf2, INFO))
+	cause := errors.New("disk full")
+
+	err := comp.ErrorErr(cause, "write failed")
+
+	assert.True(t, errors.Is(err, cause))
+	assert.EqualError(t, err, "write failed: disk full")
+	assert.Contains(t, buf1.String(), "write failed: disk full error_type=*errors.errorString")
+	assert.Contains(t, buf2.String(), "write failed: disk full error_type=*errors.errorString")
+}
+
+func TestFormatFields_Quoting(t *testing.T) {
+	fields := []Field{
+		{Key: "plain", Value: 42},
+		{Key: "spaced", Value: "a b"},
+		{Key: "quoted", Value: `say "hi"`},
+		{Key: "empty", Value: ""},
+	}
+
+	assert.Equal(t, ` plain=42 spaced="a b" quoted="say \"hi\"" empty=""`, formatFields(fields))
+}
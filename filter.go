@@ -0,0 +1,137 @@
+package glog
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Filter returns a Logger that passes to logger only the records keep returns true for. Rejected PANIC and FATAL records
+// are not written but still panic or exit. The record's Fields include the logger name set by Named, so a Named logger
+// must wrap the filtered one (or a Composite of filtered sinks), not the other way round.
+func Filter(logger Logger, keep func(Record) bool) Logger {
+	return newDerived(filter{next: logger, keep: keep}, logger)
+}
+
+// SetFilter wraps the default logger with Filter. The filter stays in place when the default logger is replaced
+// (Configure, WatchConfig, SetWriters, ToFile, ...); calling SetFilter again replaces it, and nil removes it.
+func SetFilter(keep func(Record) bool) {
+	updateDefault(func(h *defaultHolder) { h.keep = keep })
+}
+
+type filter struct {
+	next Logger
+	keep func(Record) bool
+}
+
+func (f filter) IsEnabled(logLevel LogLevel) bool {
+	return f.next.IsEnabled(logLevel)
+}
+
+func (f filter) logFields(logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	if logLevel != PANIC && logLevel != FATAL && !f.next.IsEnabled(logLevel) {
+		return
+	}
+	text := fmt.Sprintf(format, a...)
+	if f.keep(Record{Time: time.Now(), Level: logLevel, Message: text, Fields: fields}) {
+		logWithFields(f.next, logLevel, fields, "%s", text)
+		return
+	}
+	switch logLevel {
+	case PANIC:
+		panic(message{level: logLevel, format: "%s", args: []interface{}{text}, fields: fields}.String())
+	case FATAL:
+		osExit(1)
+	}
+}
+
+func (f filter) logRecord(r *Record) {
+	if f.keep(*r) {
+		logRecord(f.next, r)
+	}
+}
+
+// LevelIs matches records at any of levels.
+func LevelIs(levels ...LogLevel) func(Record) bool {
+	return func(r Record) bool {
+		for _, level := range levels {
+			if r.Level == level {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MessageMatches matches records whose formatted message (without fields) matches re.
+func MessageMatches(re *regexp.Regexp) func(Record) bool {
+	return func(r Record) bool {
+		return re.MatchString(r.Message)
+	}
+}
+
+// LoggerNamed matches records from a logger made by Named with one of names, or a descendant of it ("db" matches
+// "db.pool").
+func LoggerNamed(names ...string) func(Record) bool {
+	return func(r Record) bool {
+		name, ok := r.field(LoggerKey)
+		if !ok {
+			return false
+		}
+		for _, n := range names {
+			if name == n || len(name) > len(n) && name[:len(n)] == n && name[len(n)] == '.' {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FieldEquals matches records with a field key whose value prints as value.
+func FieldEquals(key, value string) func(Record) bool {
+	return func(r Record) bool {
+		v, ok := r.field(key)
+		return ok && v == value
+	}
+}
+
+// Not inverts a predicate, e.g. Filter(log, Not(LevelIs(DEBUG))).
+func Not(predicate func(Record) bool) func(Record) bool {
+	return func(r Record) bool {
+		return !predicate(r)
+	}
+}
+
+// AllOf matches records matching every predicate.
+func AllOf(predicates ...func(Record) bool) func(Record) bool {
+	return func(r Record) bool {
+		for _, p := range predicates {
+			if !p(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf matches records matching at least one predicate.
+func AnyOf(predicates ...func(Record) bool) func(Record) bool {
+	return func(r Record) bool {
+		for _, p := range predicates {
+			if p(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// field returns the printed value of the last field named key.
+func (r Record) field(key string) (string, bool) {
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		if r.Fields[i].Key == key {
+			return r.Fields[i].text(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,96 @@
+package glog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_DropsRejectedRecords(t *testing.T) {
+	var buf bytes.Buffer
+	log := Filter(NewWithWriters(&buf, &buf, DEBUG), Not(MessageMatches(regexp.MustCompile(`^noise`))))
+
+	log.Info("noise %d", 1)
+	log.Info("signal %d", 2)
+
+	assert.NotContains(t, buf.String(), "noise")
+	assert.Contains(t, buf.String(), "INFO signal 2")
+}
+
+func TestFilter_SilencesNamedComponentWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, DEBUG)
+	filtered := Filter(log, Not(AllOf(LoggerNamed("vendor"), LevelIs(WARN))))
+	vendor := Named(Named(filtered, "vendor"), "client")
+	app := Named(filtered, "app")
+
+	vendor.Warn("retrying")
+	_ = vendor.Error("gave up")
+	app.Warn("slow request")
+
+	assert.NotContains(t, buf.String(), "retrying")
+	assert.Contains(t, buf.String(), "ERROR gave up logger=vendor.client")
+	assert.Contains(t, buf.String(), "WARN slow request logger=app")
+}
+
+func TestFilter_FieldEquals(t *testing.T) {
+	var buf bytes.Buffer
+	log := Filter(NewWithWriters(&buf, &buf, DEBUG), Not(FieldEquals("error_type", "*errors.errorString")))
+
+	_ = log.ErrorErr(errors.New("cause"), "dropped")
+	_ = log.Error("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "ERROR kept")
+}
+
+func TestFilter_ComposesWithCompositeAndRouter(t *testing.T) {
+	var console, errorsOut, audit bytes.Buffer
+	router := NewWithWriters(&console, &console, DEBUG)
+	router.(LevelRouter).SetOutputForLevel(ERROR, &errorsOut)
+	log := Composite(
+		Filter(router, Not(LevelIs(DEBUG))),
+		NewWithWriters(&audit, &audit, DEBUG),
+	)
+
+	log.Debug("debug")
+	_ = log.Error("boom")
+
+	assert.Empty(t, console.String())
+	assert.Contains(t, errorsOut.String(), "ERROR boom")
+	assert.Contains(t, audit.String(), "DEBUG debug")
+}
+
+func TestFilter_RejectedPanicStillPanics(t *testing.T) {
+	var buf bytes.Buffer
+	log := Filter(NewWithWriters(&buf, &buf, DEBUG), Not(LevelIs(PANIC)))
+
+	assert.PanicsWithValue(t, "PANIC boom", func() { log.Panic("boom") })
+	assert.Empty(t, buf.String())
+}
+
+func TestSetFilter_SurvivesReplacedDefault(t *testing.T) {
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+	SetFilter(Not(MessageMatches(regexp.MustCompile("^healthcheck"))))
+
+	var out, errs bytes.Buffer
+	SetWriters(&out, &out, INFO)
+	Info("healthcheck ok")
+	Info("started")
+	assert.NotContains(t, out.String(), "healthcheck")
+	assert.Contains(t, out.String(), "started")
+
+	assert.True(t, SetOutputForLevel(ERROR, &errs), "router functions reach the logger under the filter")
+	_ = Error("healthcheck failed")
+	_ = Error("boom")
+	assert.NotContains(t, errs.String(), "healthcheck")
+	assert.Contains(t, errs.String(), "boom")
+
+	SetFilter(nil)
+	Info("healthcheck again")
+	assert.Contains(t, out.String(), "healthcheck again")
+}
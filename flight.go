@@ -0,0 +1,162 @@
+package glog
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FlightRecorderOptions configures NewFlightRecorder.
+type FlightRecorderOptions struct {
+	// BufferLevel is the highest level held back; default DEBUG (DEBUG and TRACE are buffered).
+	BufferLevel LogLevel
+	// TriggerLevel is the lowest level that writes out the buffered records, before itself; default ERROR.
+	TriggerLevel LogLevel
+	// MaxRecords bounds the buffer; the oldest records are dropped beyond it. Default 1000.
+	MaxRecords int
+}
+
+// FlightRecorder is a Logger for one scope, e.g. a request, that holds back low-level records and writes them only if
+// something goes wrong: a record at TriggerLevel or above first writes the buffered records to the underlying logger,
+// while Close ends the scope and discards them. Records above BufferLevel are written straight away.
+//
+// Buffered records are written with the time they were logged, even if the underlying logger's level is above them
+// (an INFO console still shows the DEBUG lines behind an error). Their messages are formatted when they are buffered,
+// so they show the state at the call; Lazy arguments are evaluated then too.
+type FlightRecorder struct {
+	derived
+	flight *flight
+}
+
+// NewFlightRecorder returns a recorder writing to logger (nil means the default logger).
+func NewFlightRecorder(logger Logger, opts ...FlightRecorderOptions) *FlightRecorder {
+	var opt FlightRecorderOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.BufferLevel == (LogLevel{}) {
+		opt.BufferLevel = DEBUG
+	}
+	if opt.TriggerLevel == (LogLevel{}) {
+		opt.TriggerLevel = ERROR
+	}
+	if opt.MaxRecords <= 0 {
+		opt.MaxRecords = 1000
+	}
+	if logger == nil {
+		logger = Default()
+	}
+	f := &flight{next: logger, opts: opt}
+	return &FlightRecorder{derived: newDerived(f, logger), flight: f}
+}
+
+// Flush writes the buffered records now.
+func (r *FlightRecorder) Flush() {
+	r.flight.flush()
+}
+
+// Close discards the buffered records; records at buffered levels logged afterwards are dropped.
+func (r *FlightRecorder) Close() {
+	r.flight.mu.Lock()
+	defer r.flight.mu.Unlock()
+	r.flight.records = nil
+	r.flight.closed = true
+}
+
+// FlightRecorderMiddleware returns middleware that gives each request a FlightRecorder over the logger in its context
+// (see HTTPMiddleware), so a handler's DEBUG records show up only for requests that log an error.
+func FlightRecorderMiddleware(opts ...FlightRecorderOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := NewFlightRecorder(FromContext(r.Context()), opts...)
+			defer recorder.Close()
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), recorder)))
+		})
+	}
+}
+
+type flight struct {
+	next Logger
+	opts FlightRecorderOptions
+
+	mu      sync.Mutex
+	records []flightRecord
+	closed  bool
+}
+
+type flightRecord struct {
+	at      time.Time
+	level   LogLevel
+	fields  []Field
+	message string
+}
+
+// buffers reports whether records at logLevel are held back rather than written.
+func (f *flight) buffers(logLevel LogLevel) bool {
+	return logLevel.weight <= f.opts.BufferLevel.weight && logLevel != PANIC && logLevel != FATAL
+}
+
+func (f *flight) IsEnabled(logLevel LogLevel) bool {
+	if f.buffers(logLevel) {
+		return !f.isClosed()
+	}
+	return f.next.IsEnabled(logLevel)
+}
+
+func (f *flight) logFields(logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	if f.buffers(logLevel) {
+		if f.isClosed() {
+			return
+		}
+		f.buffer(flightRecord{
+			at:      time.Now(),
+			level:   logLevel,
+			fields:  append([]Field(nil), fields...),
+			message: fmt.Sprintf(format, a...),
+		})
+		return
+	}
+	if logLevel != PANIC && logLevel != FATAL && !f.next.IsEnabled(logLevel) {
+		return
+	}
+	if logLevel.weight >= f.opts.TriggerLevel.weight {
+		f.flush()
+	}
+	logWithFields(f.next, logLevel, fields, format, a...)
+}
+
+func (f *flight) logRecord(r *Record) {
+	logRecord(f.next, r)
+}
+
+func (f *flight) buffer(r flightRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	if len(f.records) >= f.opts.MaxRecords {
+		f.records[0] = flightRecord{}
+		f.records = f.records[1:]
+	}
+	f.records = append(f.records, r)
+}
+
+// flush writes the held records with their original time, past the underlying logger's level.
+func (f *flight) flush() {
+	f.mu.Lock()
+	records := f.records
+	f.records = nil
+	f.mu.Unlock()
+
+	for _, r := range records {
+		logRecord(f.next, &Record{Time: r.at, Level: r.level, Message: r.message, Fields: r.fields})
+	}
+}
+
+func (f *flight) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
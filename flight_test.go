@@ -0,0 +1,129 @@
+package glog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlightRecorder_FlushesOnError(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewFlightRecorder(NewWithWriters(&buf, &buf, TRACE))
+
+	recorder.Debug("loaded %d items", 3)
+	With(recorder, Field{Key: "step", Value: "parse"}).Trace("parsing")
+	recorder.Info("request started")
+	assert.Contains(t, buf.String(), "request started")
+	assert.NotContains(t, buf.String(), "loaded")
+
+	_ = recorder.Error("failed")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[1], "DEBUG loaded 3 items")
+	assert.Contains(t, lines[2], "TRACE parsing step=parse")
+	assert.Contains(t, lines[3], "ERROR failed")
+
+	buf.Reset()
+	recorder.Debug("after error")
+	recorder.Close()
+	recorder.Debug("after close")
+	_ = recorder.Error("again")
+	assert.NotContains(t, buf.String(), "after")
+	assert.False(t, recorder.IsDebug())
+	assert.True(t, recorder.IsInfo())
+}
+
+func TestFlightRecorder_Options(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewFlightRecorder(NewWithWriters(&buf, &buf, TRACE), FlightRecorderOptions{
+		BufferLevel:  INFO,
+		TriggerLevel: WARN,
+		MaxRecords:   2,
+	})
+
+	recorder.Info("one")
+	recorder.Info("two")
+	recorder.Debug("three")
+	assert.Empty(t, buf.String())
+
+	recorder.Warn("slow")
+	assert.NotContains(t, buf.String(), "one")
+	assert.Contains(t, buf.String(), "two")
+	assert.Contains(t, buf.String(), "three")
+
+	buf.Reset()
+	recorder.Info("manual")
+	recorder.Flush()
+	assert.Contains(t, buf.String(), "manual")
+}
+
+func TestFlightRecorder_WritesPastUnderlyingLevel(t *testing.T) {
+	ring := NewRingBuffer(10)
+	recorder := NewFlightRecorder(Named(NewWithWriters(ring, ring, INFO), "api"))
+	assert.True(t, recorder.IsDebug())
+
+	recorder.Debug("context line")
+	time.Sleep(20 * time.Millisecond)
+	_ = recorder.Error("boom")
+
+	records := ring.Records()
+	require.Len(t, records, 2)
+	assert.Equal(t, DEBUG, records[0].Level)
+	assert.Equal(t, "context line", records[0].Message)
+	assert.Equal(t, []Field{{Key: LoggerKey, Value: "api"}}, records[0].Fields)
+	assert.True(t, records[1].Time.Sub(records[0].Time) >= 20*time.Millisecond, "buffered record keeps its own time")
+}
+
+func TestFlightRecorder_FormatsWhenBuffered(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewFlightRecorder(NewWithWriters(&buf, &buf, INFO))
+	state := &struct{ Step int }{Step: 1}
+
+	recorder.Debug("state %+v", state)
+	state.Step = 2
+	recorder.Debug("state %+v", state)
+	_ = recorder.Error("boom")
+
+	assert.Contains(t, buf.String(), "DEBUG state &{Step:1}")
+	assert.Contains(t, buf.String(), "DEBUG state &{Step:2}")
+}
+
+func TestFlightRecorder_FilteredSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := Filter(NewWithWriters(&buf, &buf, INFO), Not(MessageMatches(regexp.MustCompile("noise"))))
+	recorder := NewFlightRecorder(sink)
+
+	recorder.Debug("noise")
+	recorder.Debug("signal")
+	_ = recorder.Error("boom")
+
+	assert.NotContains(t, buf.String(), "noise")
+	assert.Contains(t, buf.String(), "DEBUG signal")
+}
+
+func TestFlightRecorderMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewWithWriters(&buf, &buf, INFO)
+	handler := HTTPMiddleware(base)(FlightRecorderMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := FromContext(r.Context())
+		log.Debug("handling %s", r.URL.Path)
+		if r.URL.Path == "/fail" {
+			_ = log.Error("boom")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	assert.NotContains(t, buf.String(), "handling /ok")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	assert.Contains(t, buf.String(), "handling /fail")
+	assert.Contains(t, buf.String(), "request_id=")
+}
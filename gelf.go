@@ -0,0 +1,271 @@
+package glog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GELFEncoder encodes records as GELF 1.1 JSON messages for Graylog, one per line. Fields become additional fields
+// ("_key", "_key.name" for Object fields); numbers stay numbers and other values are written as strings.
+type GELFEncoder struct {
+	// Host is the message's host; default os.Hostname().
+	Host string
+}
+
+// Encode implements Encoder.
+func (e GELFEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	host := e.Host
+	if host == "" {
+		host = hostname()
+	}
+	buf.WriteString(`{"version":"1.1","host":`)
+	writeJSONString(buf, host)
+	buf.WriteString(`,"short_message":`)
+	writeJSONString(buf, r.Message)
+	buf.WriteString(`,"timestamp":`)
+	buf.WriteString(strconv.FormatFloat(float64(r.Time.UnixNano()/int64(time.Millisecond))/1000, 'f', 3, 64))
+	buf.WriteString(`,"level":`)
+	buf.WriteString(strconv.Itoa(SyslogSeverity(r.Level)))
+	writeGELFFields(buf, "", r.Fields)
+	buf.WriteString("}\n")
+}
+
+// writeGELFFields writes fields as additional fields; GELF has no nesting, so objects become "_key.name".
+func writeGELFFields(buf *bytes.Buffer, prefix string, fields []Field) {
+	for _, f := range fields {
+		if f.kind == objectField {
+			writeGELFFields(buf, prefix+f.Key+".", f.objectFields())
+			continue
+		}
+		buf.WriteByte(',')
+		writeJSONString(buf, gelfFieldName(prefix+f.Key))
+		buf.WriteByte(':')
+		switch f.kind {
+		case intField, floatField:
+			writeJSONField(buf, f)
+		case plainField:
+			writeGELFValue(buf, f.Value)
+		default:
+			writeJSONString(buf, f.text())
+		}
+	}
+}
+
+// SyslogSeverity maps a level to a syslog severity: TRACE and DEBUG 7, INFO 6, WARN 4, ERROR 3, PANIC 2, FATAL 1.
+func SyslogSeverity(logLevel LogLevel) int {
+	switch logLevel {
+	case TRACE, DEBUG:
+		return 7
+	case INFO:
+		return 6
+	case WARN:
+		return 4
+	case ERROR:
+		return 3
+	case PANIC:
+		return 2
+	case FATAL:
+		return 1
+	}
+	return 6
+}
+
+var hostname = func() func() string {
+	var once sync.Once
+	var name string
+	return func() string {
+		once.Do(func() {
+			name, _ = os.Hostname()
+			if name == "" {
+				name = "unknown"
+			}
+		})
+		return name
+	}
+}()
+
+// gelfFieldName returns "_" + key with characters GELF does not allow replaced by '_'; "id" is reserved, so it
+// becomes "__id".
+func gelfFieldName(key string) string {
+	if key == "id" {
+		return "__id"
+	}
+	b := make([]byte, 0, len(key)+1)
+	b = append(b, '_')
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-' {
+			b = append(b, c)
+		} else {
+			b = append(b, '_')
+		}
+	}
+	return string(b)
+}
+
+func writeGELFValue(buf *bytes.Buffer, v interface{}) {
+	if !writeJSONNumber(buf, v) {
+		writeJSONString(buf, fmt.Sprint(v))
+	}
+}
+
+// GELFCompression selects how GELF UDP messages are compressed.
+type GELFCompression int
+
+const (
+	GELFNoCompression GELFCompression = iota
+	GELFGzip
+	GELFZlib
+)
+
+// GELFOptions configures NewGELFWriter.
+type GELFOptions struct {
+	// Host is the message's host; default os.Hostname().
+	Host string
+	// Compression applies to UDP only.
+	Compression GELFCompression
+	// ChunkSize is the largest UDP datagram; longer messages are chunked. Default 1420.
+	ChunkSize int
+	// Network configures TCP reconnects and buffering; its Framing is ignored.
+	Network NetworkOptions
+}
+
+// gelfMaxChunks is the GELF limit on chunks per message.
+const gelfMaxChunks = 128
+
+// GELFWriter sends records to a Graylog GELF input. Loggers using it as a writer encode records with GELFEncoder.
+type GELFWriter struct {
+	transport io.WriteCloser
+	enc       GELFEncoder
+}
+
+// NewGELFWriter returns a writer for a GELF input at address over "udp" (chunked, optionally compressed) or "tcp"
+// (null-byte framed, with NetworkWriter's reconnects and buffering).
+func NewGELFWriter(network, address string, opts ...GELFOptions) (*GELFWriter, error) {
+	var opt GELFOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var transport io.WriteCloser
+	switch network {
+	case "udp", "udp4", "udp6":
+		conn, err := net.Dial(network, address)
+		if err != nil {
+			return nil, err
+		}
+		chunkSize := opt.ChunkSize
+		if chunkSize <= gelfChunkHeader {
+			chunkSize = 1420
+		}
+		transport = &gelfUDP{conn: conn, compression: opt.Compression, chunkSize: chunkSize}
+	case "tcp", "tcp4", "tcp6":
+		networkOpts := opt.Network
+		networkOpts.Framing = NullByteFraming
+		w, err := NewNetworkWriter(network, address, networkOpts)
+		if err != nil {
+			return nil, err
+		}
+		transport = w
+	default:
+		return nil, fmt.Errorf("unsupported GELF network %q", network)
+	}
+	return &GELFWriter{transport: transport, enc: GELFEncoder{Host: opt.Host}}, nil
+}
+
+// Write sends p, one GELF JSON message, as is.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	return w.transport.Write(p)
+}
+
+// Close releases the connection; for TCP it first tries to send queued messages.
+func (w *GELFWriter) Close() error {
+	return w.transport.Close()
+}
+
+func (w *GELFWriter) encoding() (io.Writer, Encoder) {
+	return w.transport, w.enc
+}
+
+// gelfChunkHeader is the size of a chunk's header: magic bytes, message ID, sequence number and count.
+const gelfChunkHeader = 12
+
+type gelfUDP struct {
+	mu          sync.Mutex
+	conn        net.Conn
+	compression GELFCompression
+	chunkSize   int
+}
+
+func (u *gelfUDP) Write(p []byte) (int, error) {
+	message := bytes.TrimSuffix(p, []byte{'\n'})
+	compressed, err := u.compress(message)
+	if err != nil {
+		return 0, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(compressed) <= u.chunkSize {
+		if _, err := u.conn.Write(compressed); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	dataSize := u.chunkSize - gelfChunkHeader
+	count := (len(compressed) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return 0, fmt.Errorf("GELF message of %d bytes needs %d chunks, more than %d", len(compressed), count, gelfMaxChunks)
+	}
+	chunk := make([]byte, u.chunkSize)
+	chunk[0], chunk[1] = 0x1e, 0x0f
+	if _, err := rand.Read(chunk[2:10]); err != nil {
+		return 0, err
+	}
+	chunk[11] = byte(count)
+	for i := 0; i < count; i++ {
+		data := compressed[i*dataSize:]
+		if len(data) > dataSize {
+			data = data[:dataSize]
+		}
+		chunk[10] = byte(i)
+		n := copy(chunk[gelfChunkHeader:], data)
+		if _, err := u.conn.Write(chunk[:gelfChunkHeader+n]); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (u *gelfUDP) compress(message []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch u.compression {
+	case GELFGzip:
+		zw = gzip.NewWriter(&buf)
+	case GELFZlib:
+		zw = zlib.NewWriter(&buf)
+	default:
+		return message, nil
+	}
+	if _, err := zw.Write(message); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (u *gelfUDP) Close() error {
+	return u.conn.Close()
+}
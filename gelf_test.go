@@ -0,0 +1,177 @@
+package glog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELFEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	GELFEncoder{Host: "web-1"}.Encode(&buf, &Record{
+		Time:    time.Unix(1700000000, 250*int64(time.Millisecond)),
+		Level:   WARN,
+		Message: "disk \"almost\" full\n",
+		Fields:  []Field{{Key: "free_mb", Value: 120}, {Key: "id", Value: "x"}, {Key: "mount point", Value: "/data"}},
+	})
+
+	assert.True(t, strings.HasSuffix(buf.String(), "}\n"))
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &msg))
+	assert.Equal(t, map[string]interface{}{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": "disk \"almost\" full\n",
+		"timestamp":     1700000000.25,
+		"level":         float64(4),
+		"_free_mb":      float64(120),
+		"__id":          "x",
+		"_mount_point":  "/data",
+	}, msg)
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	assert.Equal(t, 7, SyslogSeverity(TRACE))
+	assert.Equal(t, 6, SyslogSeverity(INFO))
+	assert.Equal(t, 3, SyslogSeverity(ERROR))
+	assert.Equal(t, 1, SyslogSeverity(FATAL))
+}
+
+func readDatagram(t *testing.T, pc net.PacketConn) []byte {
+	buf := make([]byte, 65536)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func TestGELFWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	w, err := NewGELFWriter("udp", pc.LocalAddr().String(), GELFOptions{Host: "h"})
+	require.NoError(t, err)
+	defer w.Close()
+	log := NewWithWriters(w, w, INFO)
+	_ = log.ErrorErr(assert.AnError, "failed")
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(readDatagram(t, pc), &msg))
+	assert.Equal(t, "h", msg["host"])
+	assert.Equal(t, float64(3), msg["level"])
+	assert.Contains(t, msg["short_message"], "failed")
+	assert.Equal(t, "*errors.errorString", msg["_error_type"])
+}
+
+func TestGELFWriter_UDPChunkedGzip(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	w, err := NewGELFWriter("udp", pc.LocalAddr().String(), GELFOptions{Compression: GELFGzip, ChunkSize: 100})
+	require.NoError(t, err)
+	defer w.Close()
+	long := strings.Repeat("x", 50) + randomText(2000)
+	NewWithWriters(w, w, INFO).Info("%s", long)
+
+	first := readDatagram(t, pc)
+	require.Equal(t, []byte{0x1e, 0x0f}, first[:2])
+	count := int(first[11])
+	require.True(t, count > 1)
+	chunks := make([][]byte, count)
+	chunks[first[10]] = first[12:]
+	for i := 1; i < count; i++ {
+		chunk := readDatagram(t, pc)
+		assert.Equal(t, first[2:10], chunk[2:10], "same message id")
+		assert.True(t, len(chunk) <= 100)
+		chunks[chunk[10]] = chunk[12:]
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(bytes.Join(chunks, nil)))
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &msg))
+	assert.Equal(t, long, msg["short_message"])
+}
+
+func TestGELFWriter_UDPZlib(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	w, err := NewGELFWriter("udp", pc.LocalAddr().String(), GELFOptions{Compression: GELFZlib})
+	require.NoError(t, err)
+	defer w.Close()
+	NewWithWriters(w, w, INFO).Info("compressed")
+
+	zr, err := zlib.NewReader(bytes.NewReader(readDatagram(t, pc)))
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"short_message":"compressed"`)
+}
+
+func TestGELFWriter_TCPNullFraming(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	messages := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			messages <- msg
+		}
+	}()
+
+	w, err := NewGELFWriter("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer w.Close()
+	log := NewWithWriters(w, w, INFO)
+	log.Info("one")
+	log.Info("two")
+
+	first := receive(t, messages)
+	assert.True(t, strings.HasSuffix(first, "}\x00"))
+	assert.Contains(t, first, `"short_message":"one"`)
+	assert.Contains(t, receive(t, messages), `"short_message":"two"`)
+}
+
+func TestNewGELFWriter_InvalidNetwork(t *testing.T) {
+	_, err := NewGELFWriter("unix", "/tmp/x")
+	assert.Error(t, err)
+}
+
+// randomText returns n bytes that do not compress well.
+func randomText(n int) string {
+	var b strings.Builder
+	x := uint32(2463534242)
+	for i := 0; i < n; i++ {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		b.WriteByte('a' + byte(x%26))
+	}
+	return b.String()
+}
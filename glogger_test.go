@@ -144,3 +144,107 @@ func assertOut(t *testing.T, pl LogLevel, actual, expected, stream string) {
 
 	assert.Fail(t, "", "Unexpected %s output expected %s: [%#v] actual: [%#v]", stream, pl, expected, actual)
 }
+
+func TestThresholdOutputs_FanOutByMinLevel(t *testing.T) {
+	var console, file, alerts, pager bytes.Buffer
+	logger := NewWithWriters(&console, &console, DEBUG)
+	router := logger.(LevelRouter)
+
+	router.SetThresholdOutputs(
+		ThresholdOutput{MinLevel: DEBUG, Writer: &file},
+		ThresholdOutput{MinLevel: WARN, Writer: &alerts},
+		ThresholdOutput{MinLevel: FATAL, Writer: &pager},
+	)
+	logger.Debug("debug message")
+	logger.Warn("warn message")
+	_ = logger.Error("error message")
+	logger.Trace("trace message")
+
+	assert.Contains(t, file.String(), "DEBUG debug message")
+	assert.Contains(t, file.String(), "WARN warn message")
+	assert.Contains(t, file.String(), "ERROR error message")
+	assert.NotContains(t, alerts.String(), "debug message")
+	assert.Contains(t, alerts.String(), "WARN warn message")
+	assert.Contains(t, alerts.String(), "ERROR error message")
+	assert.Empty(t, pager.String())
+	assert.Empty(t, console.String())
+	assert.NotContains(t, file.String(), "trace message")
+}
+
+func TestThresholdOutputs_FatalReachesPager(t *testing.T) {
+	var file, pager bytes.Buffer
+	router := NewLevelRouter(nil, INFO)
+	router.SetThresholdOutputs(
+		ThresholdOutput{MinLevel: INFO, Writer: &file},
+		ThresholdOutput{MinLevel: FATAL, Writer: &pager},
+	)
+	code := 0
+	exit := osExit
+	osExit = func(c int) { code = c }
+	defer func() { osExit = exit }()
+
+	router.Fatal("disk gone")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, pager.String(), "FATAL disk gone")
+	assert.Contains(t, file.String(), "FATAL disk gone")
+}
+
+func TestThresholdOutputs_CombineWithLevelOutputs(t *testing.T) {
+	var console, errorsOnly, all bytes.Buffer
+	logger := NewWithWriters(&console, &console, INFO)
+	router := logger.(LevelRouter)
+	router.SetOutputForLevel(ERROR, &errorsOnly)
+	router.SetThresholdOutputs(ThresholdOutput{MinLevel: WARN, Writer: &all})
+
+	_ = logger.Error("boom")
+	logger.Info("hello")
+
+	assert.Contains(t, errorsOnly.String(), "ERROR boom")
+	assert.Contains(t, all.String(), "ERROR boom")
+	assert.Contains(t, console.String(), "INFO hello")
+	assert.NotContains(t, console.String(), "boom")
+
+	router.SetThresholdOutputs()
+	logger.Warn("back to console")
+	assert.Contains(t, console.String(), "WARN back to console")
+}
+
+func TestAddOutputForLevel_FansOutAndRemoves(t *testing.T) {
+	var console, file, alerts bytes.Buffer
+	logger := NewWithWriters(&console, &console, INFO)
+	router := logger.(LevelRouter)
+	router.AddOutputForLevel(ERROR, &file)
+	alert := router.AddOutputForLevel(ERROR, &alerts)
+
+	_ = logger.Error("first")
+	assert.Contains(t, file.String(), "ERROR first")
+	assert.Contains(t, alerts.String(), "ERROR first")
+	assert.Empty(t, console.String())
+
+	assert.True(t, router.RemoveOutput(alert))
+	assert.False(t, router.RemoveOutput(alert))
+	_ = logger.Error("second")
+	assert.Contains(t, file.String(), "ERROR second")
+	assert.NotContains(t, alerts.String(), "second")
+
+	router.SetOutputForLevel(ERROR, nil)
+	_ = logger.Error("third")
+	assert.NotContains(t, file.String(), "third")
+	assert.Contains(t, console.String(), "ERROR third")
+}
+
+func TestAddThresholdOutput_Removes(t *testing.T) {
+	var console, all bytes.Buffer
+	logger := NewWithWriters(&console, &console, INFO)
+	router := logger.(LevelRouter)
+	handle := router.AddThresholdOutput(WARN, &all)
+
+	logger.Warn("routed")
+	assert.Contains(t, all.String(), "WARN routed")
+
+	assert.True(t, router.RemoveOutput(handle))
+	logger.Warn("console")
+	assert.Contains(t, console.String(), "WARN console")
+	assert.False(t, router.RemoveOutput(OutputHandle{}))
+}
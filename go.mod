@@ -2,4 +2,7 @@ module github.com/andriyg76/glog
 
 go 1.12
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,180 @@
+package glog
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RequestIDKey is the field key for request IDs added by HTTPMiddleware.
+const RequestIDKey = "request_id"
+
+// HTTPOptions configures HTTPMiddleware.
+type HTTPOptions struct {
+	// RequestIDHeader is read from the request and set on the response; default "X-Request-ID".
+	RequestIDHeader string
+	// Message is the access-log message; default "http request".
+	Message string
+	// Skip, if set, disables the access log (not the request logger) for requests it returns true for, e.g. health checks.
+	Skip func(r *http.Request) bool
+}
+
+// HTTPMiddleware returns middleware that writes an access log line per request with its method, path, status, bytes,
+// duration, remote address and request ID, at ERROR for 5xx responses, WARN for 4xx and INFO otherwise. A handler panic
+// is logged as status 500 and then re-panicked (see Recover to log the panic itself). A request without an ID header
+// gets a generated one. Handlers get a request-scoped logger from FromContext carrying the ID and the trace IDs of a
+// W3C traceparent header. A nil logger means the default logger.
+func HTTPMiddleware(logger Logger, opts ...HTTPOptions) func(http.Handler) http.Handler {
+	var opt HTTPOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.RequestIDHeader == "" {
+		opt.RequestIDHeader = "X-Request-ID"
+	}
+	if opt.Message == "" {
+		opt.Message = "http request"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := r.Header.Get(opt.RequestIDHeader)
+			if id == "" {
+				id = newRequestID()
+			}
+			w.Header().Set(opt.RequestIDHeader, id)
+
+			base := logger
+			if base == nil {
+				base = Default()
+			}
+			requestLogger := With(base, Field{Key: RequestIDKey, Value: id})
+			requestLogger = WithTrace(requestLogger, r)
+			recorder := &statusRecorder{ResponseWriter: w}
+			defer func() {
+				p := recover()
+				status := recorder.statusCode()
+				if p != nil {
+					status = http.StatusInternalServerError
+				}
+				if opt.Skip == nil || !opt.Skip(r) {
+					logWithFields(requestLogger, statusLevel(status), []Field{
+						{Key: "method", Value: r.Method},
+						{Key: "path", Value: r.URL.Path},
+						{Key: "status", Value: status},
+						{Key: "bytes", Value: recorder.bytes},
+						{Key: "duration", Value: time.Since(start)},
+						{Key: "remote_addr", Value: r.RemoteAddr},
+					}, "%s", opt.Message)
+				}
+				if p != nil {
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(recorder, r.WithContext(NewContext(r.Context(), requestLogger)))
+		})
+	}
+}
+
+// statusLevel returns ERROR for 5xx, WARN for 4xx and INFO for other statuses.
+func statusLevel(status int) LogLevel {
+	switch {
+	case status >= 500:
+		return ERROR
+	case status >= 400:
+		return WARN
+	}
+	return INFO
+}
+
+func newRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// statusRecorder captures the status and body size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += n
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets WebSocket and other protocol upgrades take over the connection; it fails if the underlying writer
+// cannot be hijacked. A hijacked request is logged with status 101 unless the handler wrote another.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// ReadFrom keeps the underlying writer's io.ReaderFrom (e.g. sendfile for files) while counting the bytes.
+func (s *statusRecorder) ReadFrom(r io.Reader) (int64, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if readerFrom, ok := s.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = readerFrom.ReadFrom(r)
+	} else {
+		n, err = io.Copy(s.ResponseWriter, r)
+	}
+	s.bytes += int(n)
+	return n, err
+}
+
+// Push lets HTTP/2 handlers push through the recorder.
+func (s *statusRecorder) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := s.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func (s *statusRecorder) statusCode() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
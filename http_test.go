@@ -0,0 +1,134 @@
+package glog
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMiddleware_LogsRequest(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+	handler := HTTPMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest("GET", "/users?id=1", nil)
+	req.Header.Set("X-Request-ID", "abc123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "abc123", rec.Header().Get("X-Request-ID"))
+	assert.Contains(t, buf.String(), "INFO handling request_id=abc123")
+	assert.Regexp(t, `INFO http request request_id=abc123 method=GET path=/users status=200 bytes=5 duration=\S+ remote_addr=192\.0\.2\.1:1234`, buf.String())
+}
+
+func TestHTTPMiddleware_LevelByStatusAndGeneratedID(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+	status := http.StatusNotFound
+	handler := HTTPMiddleware(log, HTTPOptions{RequestIDHeader: "X-Trace"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/missing", nil))
+	assert.Contains(t, buf.String(), " WARN http request")
+	assert.Regexp(t, `^[0-9a-f]{16}$`, rec.Header().Get("X-Trace"))
+	assert.Contains(t, buf.String(), "request_id="+rec.Header().Get("X-Trace"))
+
+	status = http.StatusBadGateway
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/up", nil))
+	assert.Contains(t, buf.String(), "ERROR http request")
+	assert.Contains(t, buf.String(), "status=502")
+}
+
+func TestHTTPMiddleware_Skip(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+	handler := HTTPMiddleware(log, HTTPOptions{Skip: func(r *http.Request) bool { return r.URL.Path == "/healthz" }})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	assert.Empty(t, buf.String())
+}
+
+func TestHTTPMiddleware_ForwardsHijackAndReadFrom(t *testing.T) {
+	ring := NewRingBuffer(10)
+	log := NewWithWriters(ring, ring, INFO)
+	handler := HTTPMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/file" {
+			_, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("contents"))
+			assert.NoError(t, err)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\nhello")
+		_ = rw.Flush()
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/file")
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "contents", string(body))
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, _ = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: x\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+	resp, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	accessLog := func(path string) map[string]interface{} {
+		for _, record := range ring.Records() {
+			fields := map[string]interface{}{}
+			for _, f := range record.Fields {
+				fields[f.Key] = f.Value
+			}
+			if fields["path"] == path {
+				return fields
+			}
+		}
+		return nil
+	}
+	assert.Eventually(t, func() bool { return accessLog("/ws") != nil }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusSwitchingProtocols, accessLog("/ws")["status"])
+	assert.Equal(t, http.StatusOK, accessLog("/file")["status"])
+	assert.Equal(t, 8, accessLog("/file")["bytes"])
+
+	_, ok := http.ResponseWriter(&statusRecorder{ResponseWriter: httptest.NewRecorder()}).(http.Hijacker)
+	assert.True(t, ok)
+	_, _, err = (&statusRecorder{ResponseWriter: httptest.NewRecorder()}).Hijack()
+	assert.Equal(t, http.ErrNotSupported, err)
+}
+
+func TestHTTPMiddleware_LogsPanickingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+	handler := HTTPMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/crash", nil))
+	})
+	assert.Contains(t, buf.String(), "ERROR http request")
+	assert.Contains(t, buf.String(), "path=/crash status=500")
+}
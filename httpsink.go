@@ -0,0 +1,453 @@
+package glog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchEntry is one line of a batch with the time it was written.
+type BatchEntry struct {
+	Time time.Time
+	Line []byte
+}
+
+// BatchFormat builds the request body for a batch.
+type BatchFormat struct {
+	ContentType string
+	Write       func(buf *bytes.Buffer, entries []BatchEntry)
+	// Check, if set, reads the body of a 2xx response and returns how many lines the endpoint rejected and why.
+	// Rejected lines count in Dropped and are not retried.
+	Check func(body []byte) (rejected int, err error)
+}
+
+// NDJSON posts the lines as they are, one per line.
+var NDJSON = BatchFormat{
+	ContentType: "application/x-ndjson",
+	Write: func(buf *bytes.Buffer, entries []BatchEntry) {
+		for _, e := range entries {
+			buf.Write(e.Line)
+		}
+	},
+}
+
+// ElasticsearchBulk formats a batch for the _bulk API, indexing each line into index.
+func ElasticsearchBulk(index string) BatchFormat {
+	var action bytes.Buffer
+	action.WriteString(`{"index":{"_index":`)
+	writeJSONString(&action, index)
+	action.WriteString("}}\n")
+	return BatchFormat{
+		ContentType: "application/x-ndjson",
+		Write: func(buf *bytes.Buffer, entries []BatchEntry) {
+			for _, e := range entries {
+				buf.Write(action.Bytes())
+				buf.Write(e.Line)
+			}
+		},
+		Check: elasticsearchBulkCheck,
+	}
+}
+
+// elasticsearchBulkCheck counts the failed items of a _bulk response, which is 200 even when items fail.
+func elasticsearchBulkCheck(body []byte) (int, error) {
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || !resp.Errors {
+		return 0, nil
+	}
+	rejected, err := 0, error(nil)
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Status < 300 {
+				continue
+			}
+			if rejected++; err == nil {
+				err = fmt.Errorf("elasticsearch rejected a log line with status %d: %s: %s", result.Status, result.Error.Type, result.Error.Reason)
+			}
+		}
+	}
+	return rejected, err
+}
+
+// LokiPush formats a batch for Loki's /loki/api/v1/push as one stream with labels.
+func LokiPush(labels map[string]string) BatchFormat {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var stream bytes.Buffer
+	stream.WriteString(`{"streams":[{"stream":{`)
+	for i, name := range names {
+		if i > 0 {
+			stream.WriteByte(',')
+		}
+		writeJSONString(&stream, name)
+		stream.WriteByte(':')
+		writeJSONString(&stream, labels[name])
+	}
+	stream.WriteString(`},"values":[`)
+	return BatchFormat{
+		ContentType: "application/json",
+		Write: func(buf *bytes.Buffer, entries []BatchEntry) {
+			buf.Write(stream.Bytes())
+			for i, e := range entries {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				buf.WriteString(`["`)
+				buf.WriteString(strconv.FormatInt(e.Time.UnixNano(), 10))
+				buf.WriteString(`",`)
+				writeJSONString(buf, string(bytes.TrimSuffix(e.Line, []byte{'\n'})))
+				buf.WriteByte(']')
+			}
+			buf.WriteString("]}]}")
+		},
+	}
+}
+
+// HTTPBatchOptions configures NewHTTPBatchWriter.
+type HTTPBatchOptions struct {
+	// Format builds request bodies; default NDJSON.
+	Format BatchFormat
+	// Encoder encodes records for loggers writing to the sink; default JSONEncoder.
+	Encoder Encoder
+	// Headers are added to every request, e.g. Authorization.
+	Headers map[string]string
+	// Gzip compresses request bodies.
+	Gzip bool
+	// MaxBatchLines and MaxBatchBytes send a batch once it reaches either; defaults 1000 lines and 1 MiB.
+	MaxBatchLines int
+	MaxBatchBytes int
+	// FlushInterval sends a partial batch after this long; default 1s.
+	FlushInterval time.Duration
+	// MaxRetries retries failed requests (network errors, 429 and 5xx) with backoff doubling from MinBackoff up to
+	// MaxBackoff; defaults 3, 500ms and 30s. A negative MaxRetries disables retries.
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxBufferBytes bounds memory held by unsent batches; the oldest batches are dropped beyond it. Default 8 MiB.
+	MaxBufferBytes int
+	// Client sends the requests; default a client with a 10s timeout.
+	Client *http.Client
+}
+
+// HTTPBatchWriter collects lines and posts them in batches to a log ingestion endpoint. Write only buffers; a
+// background goroutine sends, so logging never waits on the endpoint. Loggers writing to it encode records with
+// HTTPBatchOptions.Encoder.
+type HTTPBatchWriter struct {
+	dropped uint64 // first for 64-bit alignment on 32-bit platforms
+
+	url  string
+	opts HTTPBatchOptions
+
+	mu       sync.Mutex
+	current  []BatchEntry
+	size     int
+	queue    [][]BatchEntry
+	queued   int
+	closed   bool
+	sending  bool
+	lastErr  error
+	kick     chan struct{}
+	flushReq chan chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewHTTPBatchWriter starts a writer posting to url.
+func NewHTTPBatchWriter(url string, opts ...HTTPBatchOptions) (*HTTPBatchWriter, error) {
+	var opt HTTPBatchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if url == "" {
+		return nil, fmt.Errorf("HTTP batch writer requires a URL")
+	}
+	if opt.Format.Write == nil {
+		opt.Format = NDJSON
+	}
+	if opt.Encoder == nil {
+		opt.Encoder = JSONEncoder{}
+	}
+	if opt.MaxBatchLines <= 0 {
+		opt.MaxBatchLines = 1000
+	}
+	if opt.MaxBatchBytes <= 0 {
+		opt.MaxBatchBytes = 1 << 20
+	}
+	if opt.FlushInterval <= 0 {
+		opt.FlushInterval = time.Second
+	}
+	if opt.MaxRetries < 0 {
+		opt.MaxRetries = 0
+	} else if opt.MaxRetries == 0 {
+		opt.MaxRetries = 3
+	}
+	if opt.MinBackoff <= 0 {
+		opt.MinBackoff = 500 * time.Millisecond
+	}
+	if opt.MaxBackoff < opt.MinBackoff {
+		opt.MaxBackoff = 30 * time.Second
+	}
+	if opt.MaxBufferBytes <= 0 {
+		opt.MaxBufferBytes = 8 << 20
+	}
+	if opt.Client == nil {
+		opt.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	w := &HTTPBatchWriter{
+		url:      url,
+		opts:     opt,
+		kick:     make(chan struct{}, 1),
+		flushReq: make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write adds p as one line to the current batch.
+func (w *HTTPBatchWriter) Write(p []byte) (int, error) {
+	line := make([]byte, len(p), len(p)+1)
+	copy(line, p)
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line, '\n')
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	w.current = append(w.current, BatchEntry{Time: time.Now(), Line: line})
+	w.size += len(line)
+	if len(w.current) >= w.opts.MaxBatchLines || w.size >= w.opts.MaxBatchBytes {
+		w.seal()
+	}
+	return len(p), nil
+}
+
+// Flush sends the buffered lines and waits until every queued batch is sent or dropped.
+func (w *HTTPBatchWriter) Flush() {
+	flushed := make(chan struct{})
+	select {
+	case w.flushReq <- flushed:
+		<-flushed
+	case <-w.stopped:
+	}
+}
+
+// Close sends the buffered lines, trying each batch once, and stops the writer.
+func (w *HTTPBatchWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.seal()
+	close(w.done)
+	w.mu.Unlock()
+
+	<-w.stopped
+	return nil
+}
+
+// Dropped returns the number of lines dropped: rejected by the endpoint, failed after retries or over MaxBufferBytes.
+func (w *HTTPBatchWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// LastError returns the error of the most recent failed request, or nil.
+func (w *HTTPBatchWriter) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+func (w *HTTPBatchWriter) encoding() (io.Writer, Encoder) {
+	return w, w.opts.Encoder
+}
+
+// seal moves the current batch to the send queue, dropping the oldest waiting batches beyond MaxBufferBytes; must be
+// called with w.mu held.
+func (w *HTTPBatchWriter) seal() {
+	if len(w.current) == 0 {
+		return
+	}
+	w.queue = append(w.queue, w.current)
+	w.queued += w.size
+	w.current, w.size = nil, 0
+	oldest := 0
+	if w.sending {
+		oldest = 1
+	}
+	for w.queued > w.opts.MaxBufferBytes && len(w.queue) > oldest+1 {
+		w.queued -= batchSize(w.queue[oldest])
+		atomic.AddUint64(&w.dropped, uint64(len(w.queue[oldest])))
+		w.queue = append(w.queue[:oldest], w.queue[oldest+1:]...)
+	}
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (w *HTTPBatchWriter) run() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.kick:
+			w.sendQueued(false)
+		case <-ticker.C:
+			w.mu.Lock()
+			w.seal()
+			w.mu.Unlock()
+			w.sendQueued(false)
+		case flushed := <-w.flushReq:
+			w.mu.Lock()
+			w.seal()
+			w.mu.Unlock()
+			w.sendQueued(false)
+			close(flushed)
+		case <-w.done:
+			w.sendQueued(true)
+			return
+		}
+	}
+}
+
+// sendQueued sends batches until the queue is empty; final sends each batch once without retries.
+func (w *HTTPBatchWriter) sendQueued(final bool) {
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			return
+		}
+		batch := w.queue[0]
+		w.sending = true
+		w.mu.Unlock()
+
+		err := w.sendWithRetry(batch, final)
+
+		w.mu.Lock()
+		w.sending = false
+		w.queued -= batchSize(batch)
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+		if err != nil {
+			w.lastErr = err
+			atomic.AddUint64(&w.dropped, uint64(len(batch)))
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *HTTPBatchWriter) sendWithRetry(batch []BatchEntry, final bool) error {
+	backoff := w.opts.MinBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.send(batch)
+		if err == nil || !retry || final || attempt >= w.opts.MaxRetries {
+			return err
+		}
+		w.mu.Lock()
+		w.lastErr = err
+		w.mu.Unlock()
+		select {
+		case <-time.After(backoff):
+		case <-w.done:
+			final = true
+		}
+		if backoff *= 2; backoff > w.opts.MaxBackoff {
+			backoff = w.opts.MaxBackoff
+		}
+	}
+}
+
+// send posts batch once; it reports whether a failure is worth retrying.
+func (w *HTTPBatchWriter) send(batch []BatchEntry) (bool, error) {
+	var body bytes.Buffer
+	if w.opts.Gzip {
+		var raw bytes.Buffer
+		w.opts.Format.Write(&raw, batch)
+		zw := gzip.NewWriter(&body)
+		_, _ = zw.Write(raw.Bytes())
+		_ = zw.Close()
+	} else {
+		w.opts.Format.Write(&body, batch)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, &body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", w.opts.Format.ContentType)
+	if w.opts.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for name, value := range w.opts.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := w.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if ok && w.opts.Format.Check != nil {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		w.checkResponse(respBody)
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if ok {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("log ingestion endpoint returned %s", resp.Status)
+}
+
+// checkResponse counts the lines a successful response says were rejected as dropped.
+func (w *HTTPBatchWriter) checkResponse(body []byte) {
+	rejected, err := w.opts.Format.Check(body)
+	if rejected <= 0 {
+		return
+	}
+	if err == nil {
+		err = fmt.Errorf("log ingestion endpoint rejected %d lines", rejected)
+	}
+	atomic.AddUint64(&w.dropped, uint64(rejected))
+	w.mu.Lock()
+	w.lastErr = err
+	w.mu.Unlock()
+}
+
+func batchSize(batch []BatchEntry) int {
+	size := 0
+	for _, e := range batch {
+		size += len(e.Line)
+	}
+	return size
+}
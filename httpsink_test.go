@@ -0,0 +1,194 @@
+package glog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ingest struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	status   []int
+}
+
+func (in *ingest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, _ := gzip.NewReader(r.Body)
+		body, _ = ioutil.ReadAll(zr)
+	} else {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.requests = append(in.requests, r)
+	in.bodies = append(in.bodies, string(body))
+	if len(in.status) > 0 {
+		w.WriteHeader(in.status[0])
+		in.status = in.status[1:]
+	}
+}
+
+func (in *ingest) received() []string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return append([]string(nil), in.bodies...)
+}
+
+func TestHTTPBatchWriter_NDJSONBySize(t *testing.T) {
+	in := &ingest{}
+	server := httptest.NewServer(in)
+	defer server.Close()
+
+	w, err := NewHTTPBatchWriter(server.URL, HTTPBatchOptions{
+		MaxBatchLines: 2,
+		FlushInterval: time.Hour,
+		Headers:       map[string]string{"Authorization": "Bearer t"},
+		Gzip:          true,
+	})
+	require.NoError(t, err)
+	log := NewWithWriters(w, w, INFO)
+	log.Info("one")
+	logWithFields(log, WARN, []Field{{Key: "n", Value: 2}}, "two")
+	log.Info("three")
+	w.Flush()
+
+	bodies := in.received()
+	require.Len(t, bodies, 2)
+	lines := strings.Split(strings.TrimSpace(bodies[0]), "\n")
+	require.Len(t, lines, 2)
+	var second map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "warn", second["level"])
+	assert.Equal(t, "two", second["msg"])
+	assert.Equal(t, float64(2), second["n"])
+	assert.Contains(t, bodies[1], `"msg":"three"`)
+	assert.Equal(t, "Bearer t", in.requests[0].Header.Get("Authorization"))
+	assert.Equal(t, "application/x-ndjson", in.requests[0].Header.Get("Content-Type"))
+	assert.NoError(t, w.Close())
+}
+
+func TestHTTPBatchWriter_FlushesByInterval(t *testing.T) {
+	in := &ingest{}
+	server := httptest.NewServer(in)
+	defer server.Close()
+
+	w, err := NewHTTPBatchWriter(server.URL, HTTPBatchOptions{FlushInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer w.Close()
+	_, _ = w.Write([]byte(`{"msg":"tick"}`))
+
+	assert.Eventually(t, func() bool { return len(in.received()) == 1 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, "{\"msg\":\"tick\"}\n", in.received()[0])
+}
+
+func TestHTTPBatchWriter_RetriesThenDrops(t *testing.T) {
+	in := &ingest{status: []int{503, 200, 400}}
+	server := httptest.NewServer(in)
+	defer server.Close()
+
+	w, err := NewHTTPBatchWriter(server.URL, HTTPBatchOptions{FlushInterval: time.Hour, MinBackoff: time.Millisecond})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("retried\n"))
+	w.Flush()
+	assert.Len(t, in.received(), 2)
+	assert.Equal(t, uint64(0), w.Dropped())
+
+	_, _ = w.Write([]byte("rejected\n"))
+	w.Flush()
+	assert.Len(t, in.received(), 3, "400 is not retried")
+	assert.Equal(t, uint64(1), w.Dropped())
+	assert.EqualError(t, w.LastError(), "log ingestion endpoint returned 400 Bad Request")
+}
+
+func TestHTTPBatchWriter_BoundedMemory(t *testing.T) {
+	in := &ingest{}
+	server := httptest.NewServer(in)
+	block := make(chan struct{})
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		in.ServeHTTP(w, r)
+	})
+	defer server.Close()
+
+	w, err := NewHTTPBatchWriter(server.URL, HTTPBatchOptions{MaxBatchLines: 1, MaxBufferBytes: 20, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("in flight\n"))
+	assert.Eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.sending
+	}, time.Second, time.Millisecond)
+	for i := 0; i < 5; i++ {
+		_, _ = w.Write([]byte("queued 12345\n"))
+	}
+	assert.Equal(t, uint64(4), w.Dropped())
+
+	close(block)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, []string{"in flight\n", "queued 12345\n"}, in.received())
+}
+
+func TestHTTPBatchWriter_Formats(t *testing.T) {
+	entries := []BatchEntry{{Time: time.Unix(0, 42), Line: []byte("{\"msg\":\"a\"}\n")}}
+	var bulk, loki bytes.Buffer
+
+	ElasticsearchBulk("logs").Write(&bulk, entries)
+	LokiPush(map[string]string{"job": "api", "env": "prod"}).Write(&loki, entries)
+
+	assert.Equal(t, "{\"index\":{\"_index\":\"logs\"}}\n{\"msg\":\"a\"}\n", bulk.String())
+	assert.Equal(t, `{"streams":[{"stream":{"env":"prod","job":"api"},"values":[["42","{\"msg\":\"a\"}"]]}]}`, loki.String())
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(loki.Bytes(), &decoded))
+}
+
+func TestHTTPBatchWriter_ElasticsearchItemErrorsCountAsDropped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"took":3,"errors":true,"items":[` +
+			`{"index":{"_index":"logs","status":201}},` +
+			`{"index":{"_index":"logs","status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse field [n]"}}},` +
+			`{"index":{"_index":"logs","status":201}}]}`))
+	}))
+	defer server.Close()
+
+	w, err := NewHTTPBatchWriter(server.URL, HTTPBatchOptions{Format: ElasticsearchBulk("logs"), FlushInterval: time.Hour})
+	require.NoError(t, err)
+	log := NewWithWriters(w, w, INFO)
+	log.Info("one")
+	log.Info("two")
+	log.Info("three")
+	w.Flush()
+
+	assert.Equal(t, uint64(1), w.Dropped())
+	require.Error(t, w.LastError())
+	assert.Contains(t, w.LastError().Error(), "mapper_parsing_exception")
+	assert.NoError(t, w.Close())
+}
+
+func TestElasticsearchBulkCheck(t *testing.T) {
+	rejected, err := elasticsearchBulkCheck([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+	assert.Equal(t, 0, rejected)
+	assert.NoError(t, err)
+
+	rejected, _ = elasticsearchBulkCheck([]byte(`{"errors":true,"items":[{"create":{"status":429}},{"index":{"status":409}}]}`))
+	assert.Equal(t, 2, rejected)
+
+	rejected, err = elasticsearchBulkCheck([]byte(`not json`))
+	assert.Equal(t, 0, rejected)
+	assert.NoError(t, err)
+}
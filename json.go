@@ -0,0 +1,166 @@
+package glog
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONEncoder writes records as one JSON object per line: {"ts":"...","level":"info","msg":"...","key":value}.
+// Numbers and booleans keep their JSON type and Object fields become nested objects; errors and other values are written
+// as strings.
+type JSONEncoder struct {
+	// TimeFormat is the layout of the ts value; defaults to time.RFC3339Nano.
+	TimeFormat string
+}
+
+// Encode implements Encoder.
+func (e JSONEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	timeFormat := e.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+	buf.WriteString(`{"ts":`)
+	writeJSONString(buf, r.Time.Format(timeFormat))
+	buf.WriteString(`,"level":"`)
+	buf.WriteString(levelName(r.Level))
+	buf.WriteString(`","msg":`)
+	writeJSONString(buf, r.Message)
+	for _, f := range r.Fields {
+		buf.WriteByte(',')
+		writeJSONString(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSONField(buf, f)
+	}
+	buf.WriteString("}\n")
+}
+
+// writeJSONField writes the value of f: typed fields without reflection, objects as nested JSON objects.
+func writeJSONField(buf *bytes.Buffer, f Field) {
+	var scratch [32]byte
+	switch f.kind {
+	case stringField:
+		writeJSONString(buf, f.str)
+	case intField:
+		buf.Write(strconv.AppendInt(scratch[:0], f.num, 10))
+	case boolField:
+		buf.Write(strconv.AppendBool(scratch[:0], f.num != 0))
+	case floatField:
+		if !writeJSONNumber(buf, f.float()) {
+			writeJSONString(buf, f.text())
+		}
+	case durationField, timeField:
+		writeJSONString(buf, f.text())
+	case objectField:
+		buf.WriteByte('{')
+		for i, sub := range f.objectFields() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, sub.Key)
+			buf.WriteByte(':')
+			writeJSONField(buf, sub)
+		}
+		buf.WriteByte('}')
+	default:
+		writeJSONValue(buf, f.Value)
+	}
+}
+
+// writeJSONValue writes numbers, booleans and nil as such and anything else as its printed string.
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch value := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+	case string:
+		writeJSONString(buf, value)
+	case error:
+		writeJSONString(buf, value.Error())
+	default:
+		if !writeJSONNumber(buf, v) {
+			writeJSONString(buf, fmt.Sprint(v))
+		}
+	}
+}
+
+// writeJSONNumber writes v if it is a Go number type JSON can represent, and reports whether it did.
+func writeJSONNumber(buf *bytes.Buffer, v interface{}) bool {
+	switch n := v.(type) {
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(n), 10))
+	case int8:
+		buf.WriteString(strconv.FormatInt(int64(n), 10))
+	case int16:
+		buf.WriteString(strconv.FormatInt(int64(n), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(n), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(n, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(n), 10))
+	case uint8:
+		buf.WriteString(strconv.FormatUint(uint64(n), 10))
+	case uint16:
+		buf.WriteString(strconv.FormatUint(uint64(n), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(n), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(n, 10))
+	case float32:
+		if math.IsNaN(float64(n)) || math.IsInf(float64(n), 0) {
+			return false
+		}
+		buf.WriteString(strconv.FormatFloat(float64(n), 'g', -1, 32))
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return false
+		}
+		buf.WriteString(strconv.FormatFloat(n, 'g', -1, 64))
+	default:
+		return false
+	}
+	return true
+}
+
+// writeJSONString writes s as a quoted JSON string.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case c == '\n':
+				buf.WriteString(`\n`)
+			case c == '\r':
+				buf.WriteString(`\r`)
+			case c == '\t':
+				buf.WriteString(`\t`)
+			case c < 0x20:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			default:
+				buf.WriteByte(c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString("\ufffd")
+		} else {
+			buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,54 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	JSONEncoder{TimeFormat: time.RFC3339}.Encode(&buf, &Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   ERROR,
+		Message: "line\nbreak \"quoted\" \x01",
+		Fields: []Field{
+			{Key: "n", Value: 3},
+			{Key: "ok", Value: true},
+			{Key: "err", Value: errors.New("boom")},
+			{Key: "nan", Value: math.NaN()},
+			{Key: "nil", Value: nil},
+			{Key: "d", Value: time.Second},
+		},
+	})
+
+	assert.Equal(t, byte('\n'), buf.Bytes()[buf.Len()-1])
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, map[string]interface{}{
+		"ts":    "2024-01-02T03:04:05Z",
+		"level": "error",
+		"msg":   "line\nbreak \"quoted\" \x01",
+		"n":     float64(3),
+		"ok":    true,
+		"err":   "boom",
+		"nan":   "NaN",
+		"nil":   nil,
+		"d":     "1s",
+	}, decoded)
+}
+
+func TestJSONEncoder_InvalidUTF8(t *testing.T) {
+	var buf bytes.Buffer
+	JSONEncoder{}.Encode(&buf, &Record{Message: "bad \xff byte"})
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "bad � byte", decoded["msg"])
+}
@@ -0,0 +1,65 @@
+package glog
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// keyTableSize bounds the number of keys remembered by LogOnce/LogEvery; least recently used keys are evicted first.
+const keyTableSize = 1024
+
+type keyEntry struct {
+	key  string
+	last time.Time
+}
+
+// keyTable is a concurrency-safe LRU of caller-supplied keys and the last time each was allowed to log.
+type keyTable struct {
+	mu    sync.Mutex
+	max   int
+	order *list.List
+	items map[string]*list.Element
+}
+
+func newKeyTable(max int) *keyTable {
+	return &keyTable{
+		max:   max,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// allow reports whether key may log at now: the first time it is seen, or once every has elapsed since it last logged.
+// every <= 0 means only once. A nil table allows everything.
+func (t *keyTable) allow(key string, every time.Duration, now time.Time) bool {
+	if t == nil {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if el, ok := t.items[key]; ok {
+		entry := el.Value.(*keyEntry)
+		t.order.MoveToFront(el)
+		if every <= 0 || now.Sub(entry.last) < every {
+			return false
+		}
+		entry.last = now
+		return true
+	}
+
+	t.items[key] = t.order.PushFront(&keyEntry{key: key, last: now})
+	if t.order.Len() > t.max {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+		delete(t.items, oldest.Value.(*keyEntry).key)
+	}
+	return true
+}
+
+func (t *keyTable) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.order.Len()
+}
@@ -0,0 +1,95 @@
+package glog
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyTable_OnceAndEvery(t *testing.T) {
+	table := newKeyTable(10)
+	now := time.Now()
+
+	assert.True(t, table.allow("once", 0, now))
+	assert.False(t, table.allow("once", 0, now.Add(time.Hour)))
+
+	assert.True(t, table.allow("every", time.Minute, now))
+	assert.False(t, table.allow("every", time.Minute, now.Add(30*time.Second)))
+	assert.True(t, table.allow("every", time.Minute, now.Add(time.Minute)))
+}
+
+func TestKeyTable_EvictsLeastRecentlyUsed(t *testing.T) {
+	table := newKeyTable(3)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		table.allow("k"+strconv.Itoa(i), 0, now)
+	}
+	table.allow("k0", 0, now) // k0 becomes most recent
+	table.allow("k3", 0, now) // evicts k1
+
+	assert.Equal(t, 3, table.len())
+	assert.False(t, table.allow("k0", 0, now))
+	assert.True(t, table.allow("k1", 0, now))
+}
+
+func TestLogger_WarnOnce(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	for i := 0; i < 3; i++ {
+		log.WarnOnce("deprecated-flag", "flag --old is deprecated")
+	}
+	log.WarnOnce("other", "other notice")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "flag --old is deprecated"))
+	assert.Contains(t, buf.String(), "other notice")
+}
+
+func TestLogger_LogOnce_DisabledLevelDoesNotConsumeKey(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	log.LogOnce(DEBUG, "key", "hidden")
+	log.(LevelSetter).SetLevel(DEBUG)
+	log.LogOnce(DEBUG, "key", "shown")
+
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "shown")
+}
+
+func TestLogger_InfoEvery(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	log.InfoEvery("tick", time.Hour, "tick")
+	log.InfoEvery("tick", time.Hour, "tick")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "tick"))
+}
+
+func TestComposite_WarnOnceLogsToAllOnce(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	comp := Composite(NewWithWriters(&buf1, &buf1, INFO), NewWithWriters(&buf2, &buf2, INFO))
+
+	comp.WarnOnce("key", "notice")
+	comp.WarnOnce("key", "notice")
+
+	assert.Equal(t, 1, strings.Count(buf1.String(), "notice"))
+	assert.Equal(t, 1, strings.Count(buf2.String(), "notice"))
+}
+
+func TestDefault_WarnOnce(t *testing.T) {
+	var out, err bytes.Buffer
+	SetWriters(&out, &err, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	WarnOnce("default-key", "default notice")
+	WarnOnce("default-key", "default notice")
+
+	assert.Equal(t, 1, strings.Count(err.String(), "default notice"))
+}
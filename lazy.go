@@ -0,0 +1,62 @@
+package glog
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// LazyValue is a log argument computed only when a message using it is actually formatted. Create it with Lazy.
+type LazyValue struct {
+	once  sync.Once
+	fn    func() interface{}
+	value interface{}
+}
+
+// Lazy wraps an expensive argument so fn runs only if the message is written by at least one output, and at most once
+// even when a Composite formats the message for several loggers:
+//
+//	log.Debug("state: %s", glog.Lazy(func() interface{} { return dump(state) }))
+//
+// Any verb and flags are applied to fn's result; %T reports *glog.LazyValue.
+func Lazy(fn func() interface{}) *LazyValue {
+	return &LazyValue{fn: fn}
+}
+
+// Value runs fn on first use and returns its result.
+func (l *LazyValue) Value() interface{} {
+	l.once.Do(func() {
+		if l.fn != nil {
+			l.value = l.fn()
+		}
+	})
+	return l.value
+}
+
+// Format implements fmt.Formatter by formatting Value() with the same verb and flags.
+func (l *LazyValue) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, formatDirective(f, verb), l.Value())
+}
+
+// String returns Value() formatted with %v.
+func (l *LazyValue) String() string {
+	return fmt.Sprint(l.Value())
+}
+
+// formatDirective rebuilds the %-directive that f and verb were parsed from.
+func formatDirective(f fmt.State, verb rune) string {
+	directive := []byte{'%'}
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			directive = append(directive, byte(flag))
+		}
+	}
+	if width, ok := f.Width(); ok {
+		directive = strconv.AppendInt(directive, int64(width), 10)
+	}
+	if precision, ok := f.Precision(); ok {
+		directive = append(directive, '.')
+		directive = strconv.AppendInt(directive, int64(precision), 10)
+	}
+	return string(append(directive, string(verb)...))
+}
@@ -0,0 +1,83 @@
+package glog
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy_NotEvaluatedWhenDisabled(t *testing.T) {
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+	calls := 0
+	value := Lazy(func() interface{} {
+		calls++
+		return "expensive"
+	})
+
+	log.Debug("state: %s", value)
+	log.Trace("state: %s", value)
+
+	assert.Equal(t, 0, calls)
+}
+
+func TestLazy_EvaluatedOnceAcrossComposite(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	comp := Composite(NewWithWriters(&buf1, &buf1, DEBUG), NewWithWriters(&buf2, &buf2, INFO))
+	calls := 0
+	value := Lazy(func() interface{} {
+		calls++
+		return "expensive"
+	})
+
+	comp.Debug("state: %s", value)
+
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, buf1.String(), "DEBUG state: expensive")
+	assert.NotContains(t, buf2.String(), "expensive")
+}
+
+func TestLazy_DefaultLogger(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+	calls := 0
+	value := Lazy(func() interface{} {
+		calls++
+		return 42
+	})
+
+	Debug("n=%d", value)
+	assert.Equal(t, 0, calls)
+
+	Info("n=%d", value)
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, out.String(), "n=42")
+}
+
+func TestLazy_PreservesVerbsAndFlags(t *testing.T) {
+	pi := Lazy(func() interface{} { return 3.14159 })
+	num := Lazy(func() interface{} { return 255 })
+	str := Lazy(func() interface{} { return "go" })
+
+	assert.Equal(t, "3.14", fmt.Sprintf("%.2f", pi))
+	assert.Equal(t, "  3.1", fmt.Sprintf("%5.1f", pi))
+	assert.Equal(t, "0xff", fmt.Sprintf("%#x", num))
+	assert.Equal(t, "+255", fmt.Sprintf("%+d", num))
+	assert.Equal(t, `"go"`, fmt.Sprintf("%q", str))
+	assert.Equal(t, "go  |", fmt.Sprintf("%-4s|", str))
+	assert.Equal(t, "go", str.String())
+}
+
+func TestLazy_FieldValue(t *testing.T) {
+	var buf bytes.Buffer
+	w := Encoded(&buf, LogfmtEncoder{})
+	log := NewWithWriters(w, w, INFO)
+
+	logWithFields(log, INFO, []Field{{Key: "dump", Value: Lazy(func() interface{} { return "a b" })}}, "with field")
+
+	assert.Contains(t, buf.String(), `msg="with field" dump="a b"`)
+}
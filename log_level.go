@@ -1,5 +1,10 @@
 package glog
 
+import (
+	"fmt"
+	"strings"
+)
+
 // LogLevel represents a log level. Use the package constants (TRACE, DEBUG, INFO, etc.).
 // Order by severity: TRACE < DEBUG < INFO < WARN < ERROR, PANIC, FATAL.
 type LogLevel struct {
@@ -53,3 +58,32 @@ var FATAL = LogLevel{
 func (l LogLevel) String() string {
 	return l.prefix
 }
+
+// Levels lists all levels from lowest to highest.
+var Levels = append([]LogLevel(nil), levelOrder[:]...)
+
+var levelOrder = [...]LogLevel{TRACE, DEBUG, INFO, WARN, ERROR, PANIC, FATAL}
+
+// levelIndex returns the position of logLevel in levelOrder, or -1.
+func levelIndex(logLevel LogLevel) int {
+	for i := range levelOrder {
+		if levelOrder[i] == logLevel {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseLevel returns the level named s, case-insensitively (e.g. "debug", "WARN"; "warning" is accepted for WARN).
+func ParseLevel(s string) (LogLevel, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warning" {
+		return WARN, nil
+	}
+	for _, l := range levelOrder {
+		if levelName(l) == name {
+			return l, nil
+		}
+	}
+	return INFO, fmt.Errorf("unknown log level %q", s)
+}
@@ -15,3 +15,18 @@ func TestLogLevel_String(t *testing.T) {
 	assert.Equal(t, "PANIC", PANIC.String())
 	assert.Equal(t, "FATAL", FATAL.String())
 }
+
+func TestParseLevel(t *testing.T) {
+	for _, l := range Levels {
+		parsed, err := ParseLevel(l.String())
+		assert.NoError(t, err)
+		assert.Equal(t, l, parsed)
+	}
+
+	parsed, err := ParseLevel("Warning")
+	assert.NoError(t, err)
+	assert.Equal(t, WARN, parsed)
+
+	_, err = ParseLevel("verbose")
+	assert.EqualError(t, err, `unknown log level "verbose"`)
+}
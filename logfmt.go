@@ -0,0 +1,43 @@
+package glog
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// LogfmtEncoder writes records as logfmt: ts=... level=info msg="..." key=value.
+// Values containing spaces, quotes, '=' or control characters are quoted and escaped.
+type LogfmtEncoder struct {
+	// TimeFormat is the layout of the ts value; defaults to time.RFC3339Nano.
+	TimeFormat string
+}
+
+// Encode implements Encoder.
+func (e LogfmtEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	timeFormat := e.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+	buf.WriteString("ts=")
+	buf.WriteString(quoteFieldValue(r.Time.Format(timeFormat)))
+	buf.WriteString(" level=")
+	buf.WriteString(levelName(r.Level))
+	buf.WriteString(" msg=")
+	buf.WriteString(quoteFieldValue(r.Message))
+	writeFields(buf, "", r.Fields, logfmtKey)
+	buf.WriteByte('\n')
+}
+
+// logfmtKey replaces characters that are not allowed in a logfmt key with '_'.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
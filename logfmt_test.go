@@ -0,0 +1,51 @@
+package glog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtEncoder_Escaping(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Record{
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   INFO,
+		Message: "line one\nline \"two\"",
+		Fields: []Field{
+			{Key: "user", Value: "alice"},
+			{Key: "query", Value: "a=b c"},
+			{Key: "empty", Value: ""},
+			{Key: "bad key", Value: 7},
+		},
+	}
+
+	LogfmtEncoder{}.Encode(&buf, r)
+
+	assert.Equal(t, `ts=2026-01-02T03:04:05Z level=info msg="line one\nline \"two\"" user=alice query="a=b c" empty="" bad_key=7`+"\n", buf.String())
+}
+
+func TestLogfmtEncoder_TimeFormat(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Record{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Level: DEBUG, Message: "x"}
+
+	LogfmtEncoder{TimeFormat: "15:04:05"}.Encode(&buf, r)
+
+	assert.Equal(t, "ts=03:04:05 level=debug msg=x\n", buf.String())
+}
+
+func TestToFileEncoded_Logfmt(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "glog_test_logfmt.txt")
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	ToFileEncoded(fpath, LogfmtEncoder{}, INFO)
+	Info("file message")
+
+	data, err := os.ReadFile(fpath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `level=info msg="file message"`)
+}
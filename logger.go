@@ -7,6 +7,7 @@ import (
 	"os"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Output writes formatted log messages. Used for level-specific writers (e.g. DebugLogger(), GetOutput).
@@ -41,8 +42,10 @@ type WarnLogger interface {
 }
 
 // ErrorLogger provides error-level logging; Error returns an error for chaining.
+// ErrorErr wraps err with %w so errors.Is/As work on the returned error, and logs err's type and chain as fields.
 type ErrorLogger interface {
 	Error(format string, a ...interface{}) error
+	ErrorErr(err error, format string, a ...interface{}) error
 	IsError() bool
 }
 
@@ -54,13 +57,36 @@ type Logger interface {
 	InfoLogger
 	ErrorLogger
 
-	// Log formats according to a format specifier
+	// Log formats according to a format specifier; Field arguments at the end are written as fields.
 	Log(LogLevel LogLevel, format string, a ...interface{})
 	IsEnabled(logLevel LogLevel) bool
 	GetOutput(LogLevel LogLevel) Output
 
 	Panic(format string, a ...interface{})
 	Fatal(format string, a ...interface{})
+
+	KeyedLogger
+	StructuredLogger
+}
+
+// StructuredLogger logs a fixed message with fields, e.g. Infow("user logged in", String("user", name), Int("attempt", n)).
+// Errorw returns an error with msg, wrapping the error of the first Err field if any.
+type StructuredLogger interface {
+	Logw(logLevel LogLevel, msg string, fields ...Field)
+	Tracew(msg string, fields ...Field)
+	Debugw(msg string, fields ...Field)
+	Infow(msg string, fields ...Field)
+	Warnw(msg string, fields ...Field)
+	Errorw(msg string, fields ...Field) error
+}
+
+// KeyedLogger logs once per caller-supplied key, or at most once per interval per key (e.g. deprecation notices).
+// Keys are kept in a bounded LRU table; an evicted key may log again.
+type KeyedLogger interface {
+	LogOnce(logLevel LogLevel, key string, format string, a ...interface{})
+	LogEvery(logLevel LogLevel, key string, every time.Duration, format string, a ...interface{})
+	WarnOnce(key string, format string, a ...interface{})
+	InfoEvery(key string, every time.Duration, format string, a ...interface{})
 }
 
 // LevelSetter allows changing the minimum log level at runtime.
@@ -68,62 +94,192 @@ type LevelSetter interface {
 	SetLevel(logLevel LogLevel)
 }
 
-// LevelRouter is a Logger that can route output per level (SetOutputForLevel, SetOutputs).
+// LevelRouter is a Logger that can route output per level (SetOutputForLevel, SetOutputs, AddOutputForLevel) and to
+// outputs with their own minimum level (SetThresholdOutputs, AddThresholdOutput). A record goes to every output for its
+// level and every threshold output it reaches; only if there is none does it go to the logger's out/err writers.
+// SetOutputForLevel and SetOutputs replace all outputs of the levels they set; the Add methods return a handle for
+// RemoveOutput.
 type LevelRouter interface {
 	Logger
 	SetOutputForLevel(logLevel LogLevel, out io.Writer)
 	SetOutputs(outputs map[LogLevel]io.Writer)
+	SetThresholdOutputs(outputs ...ThresholdOutput)
+	AddOutputForLevel(logLevel LogLevel, out io.Writer) OutputHandle
+	AddThresholdOutput(minLevel LogLevel, out io.Writer) OutputHandle
+	RemoveOutput(handle OutputHandle) bool
+}
+
+// ThresholdOutput is a writer that receives every record at MinLevel or above, in Levels order
+// (so a FATAL threshold gets only FATAL, although ERROR, PANIC and FATAL rank equally for the logger level).
+// The logger's own level is checked first.
+type ThresholdOutput struct {
+	MinLevel LogLevel
+	Writer   io.Writer
 }
 
 type logger struct {
-	level  *int32
-	out    *log.Logger
-	err    *log.Logger
-	fatalf func(format string, a ...interface{})
-	router *outputRouter
+	level   *int32
+	out     Output
+	err     Output
+	fatalf  func(format string, a ...interface{})
+	router  *outputRouter
+	keys    *keyTable
+	color   *colorState
+	outputs *levelOutputs
+	errs    *writeErrors
 }
 
 type outputRouter struct {
-	mu      sync.RWMutex
-	outputs map[LogLevel]Output
+	mu     sync.RWMutex
+	routes *routeTable
+	nextID uint64
+}
+
+// routeTable is replaced, never modified, so loggers can use it after releasing the router's lock.
+type routeTable struct {
+	outputs    map[LogLevel][]routedOutput
+	thresholds []thresholdOutput
+}
+
+type routedOutput struct {
+	id  uint64
+	out Output
+}
+
+type thresholdOutput struct {
+	routedOutput
+	rank int
+}
+
+// OutputHandle identifies an output added with AddOutputForLevel or AddThresholdOutput, for RemoveOutput.
+type OutputHandle struct {
+	id uint64
 }
 
 func newOutputRouter() *outputRouter {
 	return &outputRouter{
-		outputs: make(map[LogLevel]Output),
+		routes: &routeTable{outputs: make(map[LogLevel][]routedOutput)},
 	}
 }
 
+// update replaces the route table with a modified copy of it; must be called with r.mu held.
+func (r *outputRouter) update(modify func(outputs map[LogLevel][]routedOutput, thresholds []thresholdOutput) []thresholdOutput) {
+	outputs := make(map[LogLevel][]routedOutput, len(r.routes.outputs))
+	for level, routed := range r.routes.outputs {
+		outputs[level] = routed
+	}
+	thresholds := append([]thresholdOutput(nil), r.routes.thresholds...)
+	r.routes = &routeTable{outputs: outputs, thresholds: modify(outputs, thresholds)}
+}
+
+func (r *outputRouter) newID() uint64 {
+	r.nextID++
+	return r.nextID
+}
+
 func (r *outputRouter) SetOutput(level LogLevel, out Output) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if out == nil {
-		delete(r.outputs, level)
-		return
-	}
-
-	r.outputs[level] = out
+	r.update(func(outputs map[LogLevel][]routedOutput, thresholds []thresholdOutput) []thresholdOutput {
+		if out == nil {
+			delete(outputs, level)
+		} else {
+			outputs[level] = []routedOutput{{id: r.newID(), out: out}}
+		}
+		return thresholds
+	})
 }
 
 func (r *outputRouter) SetOutputs(outputs map[LogLevel]Output) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.outputs = make(map[LogLevel]Output, len(outputs))
-	for level, out := range outputs {
-		if out != nil {
-			r.outputs[level] = out
+	r.update(func(routed map[LogLevel][]routedOutput, thresholds []thresholdOutput) []thresholdOutput {
+		for level := range routed {
+			delete(routed, level)
 		}
-	}
+		for level, out := range outputs {
+			if out != nil {
+				routed[level] = []routedOutput{{id: r.newID(), out: out}}
+			}
+		}
+		return thresholds
+	})
+}
+
+func (r *outputRouter) AddOutput(level LogLevel, out Output) OutputHandle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.newID()
+	r.update(func(outputs map[LogLevel][]routedOutput, thresholds []thresholdOutput) []thresholdOutput {
+		outputs[level] = append(append([]routedOutput(nil), outputs[level]...), routedOutput{id: id, out: out})
+		return thresholds
+	})
+	return OutputHandle{id: id}
+}
+
+func (r *outputRouter) SetThresholds(outputs []Output, minLevels []LogLevel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.update(func(_ map[LogLevel][]routedOutput, _ []thresholdOutput) []thresholdOutput {
+		thresholds := make([]thresholdOutput, len(outputs))
+		for i, out := range outputs {
+			thresholds[i] = thresholdOutput{routedOutput: routedOutput{id: r.newID(), out: out}, rank: levelIndex(minLevels[i])}
+		}
+		return thresholds
+	})
+}
+
+func (r *outputRouter) AddThreshold(minLevel LogLevel, out Output) OutputHandle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.newID()
+	r.update(func(_ map[LogLevel][]routedOutput, thresholds []thresholdOutput) []thresholdOutput {
+		return append(thresholds, thresholdOutput{routedOutput: routedOutput{id: id, out: out}, rank: levelIndex(minLevel)})
+	})
+	return OutputHandle{id: id}
+}
+
+// Remove drops the output with the handle's id; it reports whether it was found.
+func (r *outputRouter) Remove(handle OutputHandle) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := false
+	r.update(func(outputs map[LogLevel][]routedOutput, thresholds []thresholdOutput) []thresholdOutput {
+		for level, routed := range outputs {
+			for i, o := range routed {
+				if o.id == handle.id {
+					found = true
+					kept := append(append([]routedOutput(nil), routed[:i]...), routed[i+1:]...)
+					if len(kept) == 0 {
+						delete(outputs, level)
+					} else {
+						outputs[level] = kept
+					}
+					return thresholds
+				}
+			}
+		}
+		for i, t := range thresholds {
+			if t.id == handle.id {
+				found = true
+				return append(thresholds[:i], thresholds[i+1:]...)
+			}
+		}
+		return thresholds
+	})
+	return found
 }
 
-func (r *outputRouter) OutputFor(level LogLevel) (Output, bool) {
+func (r *outputRouter) table() *routeTable {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-
-	out, ok := r.outputs[level]
-	return out, ok
+	return r.routes
 }
 
 type discardWriter struct{}
@@ -139,18 +295,71 @@ func newLevelPointer(logLevel LogLevel) *int32 {
 	return &level
 }
 
-func newStdLogger(writer io.Writer) *log.Logger {
+// textOutput writes "2006/01/02 15:04:05 " followed by the formatted message, like log.LstdFlags, using pooled buffers.
+type textOutput struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newTextOutput(writer io.Writer) *textOutput {
 	if writer == nil {
 		writer = discardWriterInstance
 	}
-	return log.New(writer, "", log.LstdFlags)
+	return &textOutput{w: writer}
+}
+
+func (o *textOutput) Printf(format string, a ...interface{}) {
+	o.print(time.Now(), "", format, a, nil, nil)
+}
+
+// print writes prefix, a space and the formatted message followed by fields; an empty prefix writes the message only.
+// Write failures are reported to errs.
+func (o *textOutput) print(at time.Time, prefix string, format string, a []interface{}, fields []Field, errs *writeErrors) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	var ts [24]byte
+	buf.Write(at.AppendFormat(ts[:0], "2006/01/02 15:04:05 "))
+	if prefix != "" {
+		buf.WriteString(prefix)
+		buf.WriteByte(' ')
+	}
+	fmt.Fprintf(buf, format, a...)
+	writeFields(buf, "", fields, nil)
+	if b := buf.Bytes(); b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	writeLine(&o.mu, o.w, buf.Bytes(), errs)
+}
+
+// newOutput returns writers that take records (e.g. RingBuffer) as they are, an encoding Output for writers made with
+// Encoded, otherwise a text Output.
+func newOutput(writer io.Writer) Output {
+	if out, ok := writer.(recordOutput); ok {
+		return out
+	}
+	if encoded, ok := writer.(encodingWriter); ok {
+		return newEncodedOutput(encoded.encoding())
+	}
+	return newTextOutput(writer)
+}
+
+// fatalfFor returns a Fatalf for out: printing then exiting.
+func fatalfFor(out Output) func(format string, a ...interface{}) {
+	return func(format string, a ...interface{}) {
+		out.Printf(format, a...)
+		osExit(1)
+	}
 }
 
+var osExit = os.Exit
+
 func outputFromWriter(writer io.Writer) Output {
 	if writer == nil {
 		return nil
 	}
-	return newStdLogger(writer)
+	return newOutput(writer)
 }
 
 func outputsFromWriters(outputs map[LogLevel]io.Writer) map[LogLevel]Output {
@@ -160,7 +369,7 @@ func outputsFromWriters(outputs map[LogLevel]io.Writer) map[LogLevel]Output {
 	converted := make(map[LogLevel]Output, len(outputs))
 	for level, writer := range outputs {
 		if writer != nil {
-			converted[level] = newStdLogger(writer)
+			converted[level] = newOutput(writer)
 		}
 	}
 	return converted
@@ -197,43 +406,49 @@ func (l logger) currentLevelWeight() int32 {
 }
 
 func create(logLevel LogLevel) logger {
-	return logger{
-		level:  newLevelPointer(logLevel),
-		err:    _stderr,
-		out:    _stdout,
-		fatalf: _stderr.Fatalf,
-		router: newOutputRouter(),
-	}
+	return newLogger(_stdout.Writer(), _stderr.Writer(), logLevel)
 }
 
 func createWithWriters(out io.Writer, err io.Writer, logLevel LogLevel) logger {
-	outLogger := newStdLogger(out)
-	errLogger := newStdLogger(err)
-	return logger{
+	if out == nil {
+		out = discardWriterInstance
+	}
+	if err == nil {
+		err = discardWriterInstance
+	}
+	return newLogger(out, err, logLevel)
+}
+
+func newLogger(out io.Writer, err io.Writer, logLevel LogLevel) logger {
+	errOutput := newOutput(err)
+	l := logger{
 		level:  newLevelPointer(logLevel),
-		err:    errLogger,
-		out:    outLogger,
-		fatalf: errLogger.Fatalf,
+		err:    errOutput,
+		out:    newOutput(out),
+		fatalf: fatalfFor(errOutput),
 		router: newOutputRouter(),
+		keys:   newKeyTable(keyTableSize),
+		color:  newColorState(out, err),
+		errs:   newWriteErrors(),
 	}
+	return l.withLevelOutputs()
 }
 
-func createFileLogger(file string, level ...LogLevel) (logger, error) {
+func createFileLogger(file string, enc Encoder, level ...LogLevel) (logger, error) {
 	logLevel := INFO
 	if len(level) > 0 {
 		logLevel = level[0]
 	}
 
-	instance := create(logLevel)
-	openFile, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND, 0644)
+	openFile, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
-		return instance, Error("Error creating file %s output: %s", file, err)
+		return create(logLevel), Error("Error creating file %s output: %s", file, err)
+	}
+	var writer io.Writer = openFile
+	if enc != nil {
+		writer = Encoded(openFile, enc)
 	}
-	w := newStdLogger(openFile)
-	instance.err = w
-	instance.out = w
-	instance.fatalf = w.Fatalf
-	return instance, nil
+	return newLogger(writer, writer, logLevel), nil
 }
 
 // Create returns a new Logger with the given minimum level (default stdout/stderr).
@@ -260,26 +475,32 @@ func NewLevelRouter(outputs map[LogLevel]io.Writer, level ...LogLevel) LevelRout
 var _stdout = log.New(os.Stdout, "", log.LstdFlags)
 var _stderr = log.New(os.Stderr, "", log.LstdFlags)
 
-type dumbLogger struct{}
-
-func (d dumbLogger) Printf(format string, objs ...interface{}) {}
-
-var dumbLoggerInstance = dumbLogger{}
-
 type loggerWithLevel struct {
-	logLevel *LogLevel
-	logger   *logger
+	logLevel LogLevel
+	logger   logger
 }
 
-func (l loggerWithLevel) Printf(format string, objs ...interface{}) {
-	l.logger.Log(*l.logLevel, format, objs...)
+func (l *loggerWithLevel) Printf(format string, objs ...interface{}) {
+	l.logger.Log(l.logLevel, format, objs...)
+}
+
+// levelOutputs holds one GetOutput result per level, built once so GetOutput does not allocate.
+type levelOutputs [len(levelOrder)]Output
+
+func (l logger) withLevelOutputs() logger {
+	outputs := new(levelOutputs)
+	l.outputs = outputs
+	for i, logLevel := range levelOrder {
+		outputs[i] = &loggerWithLevel{logLevel: logLevel, logger: l}
+	}
+	return l
 }
 
 func (l logger) GetOutput(logLevel LogLevel) Output {
-	return loggerWithLevel{
-		logLevel: &logLevel,
-		logger:   &l,
+	if i := levelIndex(logLevel); i >= 0 && l.outputs != nil {
+		return l.outputs[i]
 	}
+	return &loggerWithLevel{logLevel: logLevel, logger: l}
 }
 
 func (l logger) TraceLogger() Output {
@@ -293,6 +514,26 @@ func (l logger) SetLevel(logLevel LogLevel) {
 	atomic.StoreInt32(l.level, int32(logLevel.weight))
 }
 
+func (l logger) SetColor(mode ColorMode, palette ...Palette) {
+	if l.color == nil {
+		return
+	}
+	l.color.set(mode, palette...)
+}
+
+func (l logger) SetErrorHandler(handler ErrorHandler) {
+	l.errs.setHandler(handler)
+}
+
+func (l logger) LastWriteError() error {
+	err, _ := l.errs.lastError()
+	return err
+}
+
+func (l logger) lastWriteError() (error, time.Time) {
+	return l.errs.lastError()
+}
+
 func (l logger) SetOutputForLevel(logLevel LogLevel, out io.Writer) {
 	if l.router == nil {
 		return
@@ -307,31 +548,97 @@ func (l logger) SetOutputs(outputs map[LogLevel]io.Writer) {
 	l.router.SetOutputs(outputsFromWriters(outputs))
 }
 
+func (l logger) SetThresholdOutputs(outputs ...ThresholdOutput) {
+	if l.router == nil {
+		return
+	}
+	var converted []Output
+	var minLevels []LogLevel
+	for _, t := range outputs {
+		if t.Writer != nil {
+			converted = append(converted, newOutput(t.Writer))
+			minLevels = append(minLevels, t.MinLevel)
+		}
+	}
+	l.router.SetThresholds(converted, minLevels)
+}
+
+func (l logger) AddOutputForLevel(logLevel LogLevel, out io.Writer) OutputHandle {
+	if l.router == nil || out == nil {
+		return OutputHandle{}
+	}
+	return l.router.AddOutput(logLevel, newOutput(out))
+}
+
+func (l logger) AddThresholdOutput(minLevel LogLevel, out io.Writer) OutputHandle {
+	if l.router == nil || out == nil {
+		return OutputHandle{}
+	}
+	return l.router.AddThreshold(minLevel, newOutput(out))
+}
+
+func (l logger) RemoveOutput(handle OutputHandle) bool {
+	if l.router == nil || handle.id == 0 {
+		return false
+	}
+	return l.router.Remove(handle)
+}
+
 func (l logger) Log(logLevel LogLevel, format string, objs ...interface{}) {
-	logFormat := logLevel.prefix + " " + format
+	if logLevel != PANIC && logLevel != FATAL && !l.IsEnabled(logLevel) {
+		return
+	}
+	fields, objs := splitFields(objs)
+	l.logFields(logLevel, fields, format, objs...)
+}
+
+func (l logger) Logw(logLevel LogLevel, msg string, fields ...Field) {
+	format, args := messageFormat(msg)
+	l.logFields(logLevel, fields, format, args...)
+}
+
+func (l logger) Tracew(msg string, fields ...Field) {
+	l.Logw(TRACE, msg, fields...)
+}
+
+func (l logger) Debugw(msg string, fields ...Field) {
+	l.Logw(DEBUG, msg, fields...)
+}
+
+func (l logger) Infow(msg string, fields ...Field) {
+	l.Logw(INFO, msg, fields...)
+}
+
+func (l logger) Warnw(msg string, fields ...Field) {
+	l.Logw(WARN, msg, fields...)
+}
+
+func (l logger) Errorw(msg string, fields ...Field) error {
+	l.Logw(ERROR, msg, fields...)
+	return fieldsError(msg, fields)
+}
 
+func (l logger) logFields(logLevel LogLevel, fields []Field, format string, objs ...interface{}) {
 	if logLevel == PANIC {
-		if out, ok := l.outputForLevel(logLevel); ok {
-			if panicOut, ok := out.(interface {
-				Panicf(format string, a ...interface{})
-			}); ok {
-				panicOut.Panicf(logFormat, objs...)
-				return
-			}
+		m := message{level: logLevel, format: format, args: objs, fields: fields}
+		if !l.route(m) {
+			l.write(l.err, l.color.prefix(logLevel, true), m)
 		}
-		l.err.Panicf(logFormat, objs...)
-		return
+		panic(m.String())
 	}
 	if logLevel == FATAL {
-		if out, ok := l.outputForLevel(logLevel); ok {
-			if fatalOut, ok := out.(interface {
-				Fatalf(format string, a ...interface{})
-			}); ok {
-				fatalOut.Fatalf(logFormat, objs...)
-				return
-			}
+		m := message{level: logLevel, format: format, args: objs, fields: fields}
+		if l.route(m) {
+			osExit(1)
+			return
+		}
+		if _, ok := l.err.(recordOutput); ok {
+			l.write(l.err, logLevel.prefix, m)
+			osExit(1)
+			return
 		}
-		l.fatalf(logFormat, objs...)
+		format, args := m.text(l.color.prefix(logLevel, true))
+		l.fatalf(format, args...)
 		return
 	}
 
@@ -339,24 +646,65 @@ func (l logger) Log(logLevel LogLevel, format string, objs ...interface{}) {
 		return
 	}
 
-	if out, ok := l.outputForLevel(logLevel); ok {
-		out.Printf(logFormat, objs...)
+	l.dispatch(message{level: logLevel, format: format, args: objs, fields: fields})
+}
+
+func (l logger) onceKeys() *keyTable {
+	return l.keys
+}
+
+// logRecord writes r whatever the logger's level, keeping its time; a PANIC record does not panic. r must not be FATAL.
+func (l logger) logRecord(r *Record) {
+	l.dispatch(message{level: r.Level, format: "%s", args: []interface{}{r.Message}, fields: r.Fields, at: r.Time})
+}
+
+// dispatch writes m to its routed outputs, or else to err (WARN and above) or out.
+func (l logger) dispatch(m message) {
+	if l.route(m) {
 		return
 	}
-
-	if logLevel.weight >= WARN.weight {
-		l.err.Printf(logFormat, objs...)
+	if m.level.weight >= WARN.weight {
+		l.write(l.err, l.color.prefix(m.level, true), m)
 		return
 	}
-
-	l.out.Printf(logFormat, objs...)
+	l.write(l.out, l.color.prefix(m.level, false), m)
 }
 
-func (l logger) outputForLevel(logLevel LogLevel) (Output, bool) {
+// route writes m to the output set for its level and to every threshold output it reaches.
+// It reports false if there is none, so the caller falls back to out/err.
+func (l logger) route(m message) bool {
 	if l.router == nil {
-		return nil, false
+		return false
+	}
+	table := l.router.table()
+	routed := false
+	for _, o := range table.outputs[m.level] {
+		l.write(o.out, m.level.prefix, m)
+		routed = true
+	}
+	if len(table.thresholds) > 0 {
+		rank := levelIndex(m.level)
+		for _, t := range table.thresholds {
+			if rank >= t.rank {
+				l.write(t.out, m.level.prefix, m)
+				routed = true
+			}
+		}
+	}
+	return routed
+}
+
+// write encodes m as a Record for encoding outputs, otherwise prints it as text after prefix.
+func (l logger) write(out Output, prefix string, m message) {
+	switch o := out.(type) {
+	case recordOutput:
+		o.writeRecord(m.record(), l.errs)
+	case *textOutput:
+		o.print(m.time(), prefix, m.format, m.args, m.fields, l.errs)
+	default:
+		format, args := m.text(prefix)
+		out.Printf(format, args...)
 	}
-	return l.router.OutputFor(logLevel)
 }
 
 func (l logger) Debug(format string, objs ...interface{}) {
@@ -380,11 +728,37 @@ func (l logger) Warn(format string, objs ...interface{}) {
 }
 
 func (l logger) Error(format string, objs ...interface{}) error {
+	fields, objs := splitFields(objs)
 	err := fmt.Errorf(format, objs...)
-	l.Log(ERROR, "%s", err)
+	l.logFields(ERROR, fields, "%s", err)
 	return err
 }
 
+func (l logger) ErrorErr(err error, format string, objs ...interface{}) error {
+	wrapped := wrapError(err, format, objs...)
+	l.logFields(ERROR, errorFields(err), "%s", wrapped)
+	return wrapped
+}
+
+func (l logger) LogOnce(logLevel LogLevel, key string, format string, objs ...interface{}) {
+	l.LogEvery(logLevel, key, 0, format, objs...)
+}
+
+func (l logger) LogEvery(logLevel LogLevel, key string, every time.Duration, format string, objs ...interface{}) {
+	if !l.IsEnabled(logLevel) || !l.keys.allow(key, every, time.Now()) {
+		return
+	}
+	l.Log(logLevel, format, objs...)
+}
+
+func (l logger) WarnOnce(key string, format string, objs ...interface{}) {
+	l.LogOnce(WARN, key, format, objs...)
+}
+
+func (l logger) InfoEvery(key string, every time.Duration, format string, objs ...interface{}) {
+	l.LogEvery(INFO, key, every, format, objs...)
+}
+
 func (l logger) Panic(format string, objs ...interface{}) {
 	l.Log(PANIC, format, objs...)
 }
@@ -0,0 +1,39 @@
+package glog
+
+// LoggerKey is the field key Named loggers add to every record.
+const LoggerKey = "logger"
+
+// Named returns a Logger that adds a logger=name field to every record logged through it, so outputs and filters can
+// tell components apart. Naming a named logger joins the names with a dot ("db" then "pool" gives "db.pool").
+func Named(logger Logger, name string) Logger {
+	if d, ok := logger.(derived); ok {
+		if parent, ok := d.core.(named); ok {
+			return newDerived(named{next: parent.next, name: parent.name + "." + name}, parent.next)
+		}
+	}
+	return newDerived(named{next: logger, name: name}, logger)
+}
+
+type named struct {
+	next Logger
+	name string
+}
+
+func (n named) IsEnabled(logLevel LogLevel) bool {
+	return n.next.IsEnabled(logLevel)
+}
+
+func (n named) logFields(logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	if logLevel != PANIC && logLevel != FATAL && !n.next.IsEnabled(logLevel) {
+		return
+	}
+	withName := make([]Field, 0, len(fields)+1)
+	withName = append(withName, Field{Key: LoggerKey, Value: n.name})
+	logWithFields(n.next, logLevel, append(withName, fields...), format, a...)
+}
+
+func (n named) logRecord(r *Record) {
+	withName := *r
+	withName.Fields = append(append(make([]Field, 0, len(r.Fields)+1), Field{Key: LoggerKey, Value: n.name}), r.Fields...)
+	logRecord(n.next, &withName)
+}
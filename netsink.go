@@ -0,0 +1,488 @@
+package glog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Framing is how a NetworkWriter delimits lines on the wire.
+type Framing int
+
+const (
+	// NewlineFraming sends each line terminated by '\n'.
+	NewlineFraming Framing = iota
+	// LengthPrefixFraming sends each line without its newline, after its length as a 4-byte big-endian integer.
+	LengthPrefixFraming
+	// NullByteFraming sends each line with its newline replaced by a null byte, as GELF over TCP expects.
+	NullByteFraming
+)
+
+// NetworkOptions configures NewNetworkWriter.
+type NetworkOptions struct {
+	Framing Framing
+	// MinBackoff and MaxBackoff bound the wait between reconnect attempts, doubling after each failure;
+	// defaults 100ms and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// DialTimeout and WriteTimeout bound each connect and send; defaults 5s.
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+	// BufferSize is the most bytes of unsent lines to keep; the oldest are dropped beyond it. Default 1 MiB.
+	BufferSize int
+	// BufferPath, if set, keeps unsent lines in this file, so they survive a restart. Lines are moved from memory to the
+	// file by the background goroutine, never by Write.
+	BufferPath string
+}
+
+// ErrWriterClosed is returned by writes to a closed NetworkWriter.
+var ErrWriterClosed = errors.New("glog: writer closed")
+
+// NetworkWriter ships lines to a TCP, UDP or Unix socket endpoint, e.g. a Fluent Bit or Vector socket input. Write only
+// queues the line; a background goroutine sends it, reconnecting with backoff, so a slow or absent endpoint never
+// blocks logging. Use it as a logger's writer (one line per Write), optionally with Encoded.
+type NetworkWriter struct {
+	dropped uint64 // first for 64-bit alignment on 32-bit platforms
+
+	network string
+	address string
+	opts    NetworkOptions
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  *memoryQueue
+	closed bool
+
+	// disk holds lines when BufferPath is set; only the sending goroutine (and Close, once it stopped) touch it.
+	disk    *diskQueue
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewNetworkWriter starts a writer for network ("tcp", "udp" or "unix") and address. The first connection is made in
+// the background; an error is returned only for invalid arguments or an unusable BufferPath.
+func NewNetworkWriter(network, address string, opts ...NetworkOptions) (*NetworkWriter, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix":
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+	var opt NetworkOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MinBackoff <= 0 {
+		opt.MinBackoff = 100 * time.Millisecond
+	}
+	if opt.MaxBackoff < opt.MinBackoff {
+		opt.MaxBackoff = 30 * time.Second
+	}
+	if opt.DialTimeout <= 0 {
+		opt.DialTimeout = 5 * time.Second
+	}
+	if opt.WriteTimeout <= 0 {
+		opt.WriteTimeout = 5 * time.Second
+	}
+	if opt.BufferSize <= 0 {
+		opt.BufferSize = 1 << 20
+	}
+
+	var disk *diskQueue
+	if opt.BufferPath != "" {
+		var err error
+		if disk, err = openDiskQueue(opt.BufferPath, opt.BufferSize); err != nil {
+			return nil, err
+		}
+	}
+	w := &NetworkWriter{
+		network: network,
+		address: address,
+		opts:    opt,
+		queue:   &memoryQueue{max: opt.BufferSize},
+		disk:    disk,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w, nil
+}
+
+// Write queues p as one framed line.
+func (w *NetworkWriter) Write(p []byte) (int, error) {
+	frame := w.frame(p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	dropped, err := w.queue.push(frame)
+	if dropped > 0 {
+		atomic.AddUint64(&w.dropped, uint64(dropped))
+	}
+	if err != nil {
+		return 0, err
+	}
+	w.cond.Signal()
+	return len(p), nil
+}
+
+// Dropped returns the number of lines dropped because the buffer was full.
+func (w *NetworkWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close stops accepting lines and makes one last attempt to send the queued ones. Lines that cannot be sent stay in
+// BufferPath, if set, for the next writer using it.
+func (w *NetworkWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	<-w.stopped
+	if w.disk == nil {
+		return nil
+	}
+	w.spool(w.queue.take())
+	return w.disk.close()
+}
+
+func (w *NetworkWriter) frame(p []byte) []byte {
+	line := p
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+	switch w.opts.Framing {
+	case LengthPrefixFraming:
+		frame := make([]byte, 4+len(line))
+		binary.BigEndian.PutUint32(frame, uint32(len(line)))
+		copy(frame[4:], line)
+		return frame
+	case NullByteFraming:
+		frame := make([]byte, len(line)+1)
+		copy(frame, line)
+		return frame
+	}
+	frame := make([]byte, len(p), len(p)+1)
+	copy(frame, p)
+	if len(frame) == 0 || frame[len(frame)-1] != '\n' {
+		frame = append(frame, '\n')
+	}
+	return frame
+}
+
+// run sends queued frames until the writer is closed and the queue is empty or unreachable.
+func (w *NetworkWriter) run() {
+	defer close(w.stopped)
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+	backoff := w.opts.MinBackoff
+
+	for {
+		frame, id, ok := w.next()
+		if !ok {
+			return
+		}
+		if conn == nil {
+			var err error
+			conn, err = net.DialTimeout(w.network, w.address, w.opts.DialTimeout)
+			if err != nil {
+				conn = nil
+				if w.isClosed() || !w.sleep(backoff) {
+					return
+				}
+				if backoff *= 2; backoff > w.opts.MaxBackoff {
+					backoff = w.opts.MaxBackoff
+				}
+				continue
+			}
+			backoff = w.opts.MinBackoff
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(w.opts.WriteTimeout))
+		if _, err := conn.Write(frame); err != nil {
+			_ = conn.Close()
+			conn = nil
+			if w.isClosed() {
+				return
+			}
+			continue
+		}
+		w.pop(id)
+	}
+}
+
+// next waits for a queued frame; it reports false once the writer is closed and nothing is left to send.
+func (w *NetworkWriter) next() ([]byte, uint64, bool) {
+	if w.disk != nil {
+		return w.nextFromDisk()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for {
+		if frame, id, ok := w.queue.peek(); ok {
+			return frame, id, true
+		}
+		if w.closed {
+			return nil, 0, false
+		}
+		w.cond.Wait()
+	}
+}
+
+// nextFromDisk moves the frames queued by Write to the disk queue, doing the file I/O without holding the lock Write
+// takes, and returns the oldest frame on disk.
+func (w *NetworkWriter) nextFromDisk() ([]byte, uint64, bool) {
+	for {
+		w.mu.Lock()
+		for len(w.queue.frames) == 0 && !w.closed && w.disk.empty() {
+			w.cond.Wait()
+		}
+		frames, closed := w.queue.take(), w.closed
+		w.mu.Unlock()
+
+		w.spool(frames)
+		if frame, id, ok := w.disk.peek(); ok {
+			return frame, id, true
+		}
+		if closed {
+			return nil, 0, false
+		}
+	}
+}
+
+// spool appends frames to the disk queue; frames it drops or cannot write count as dropped.
+func (w *NetworkWriter) spool(frames [][]byte) {
+	for _, frame := range frames {
+		dropped, err := w.disk.push(frame)
+		if err != nil {
+			dropped++
+		}
+		if dropped > 0 {
+			atomic.AddUint64(&w.dropped, uint64(dropped))
+		}
+	}
+}
+
+// pop removes the frame with id once it was sent.
+func (w *NetworkWriter) pop(id uint64) {
+	if w.disk != nil {
+		w.disk.pop(id)
+		return
+	}
+	w.mu.Lock()
+	w.queue.pop(id)
+	w.mu.Unlock()
+}
+
+func (w *NetworkWriter) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+// sleep waits for d; it reports false if the writer was closed meanwhile.
+func (w *NetworkWriter) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// memoryQueue and diskQueue hold unsent frames, oldest first; callers serialize access. push adds a frame, dropping the
+// oldest ones beyond the size limit, and returns how many were dropped. peek returns the oldest frame with an id, and
+// pop removes it only if it is still the oldest: push may have dropped it while it was being sent.
+type memoryQueue struct {
+	frames [][]byte
+	size   int
+	max    int
+	popped uint64
+}
+
+func (q *memoryQueue) push(frame []byte) (int, error) {
+	q.frames = append(q.frames, frame)
+	q.size += len(frame)
+	dropped := 0
+	for q.size > q.max && len(q.frames) > 1 {
+		q.pop(q.popped)
+		dropped++
+	}
+	return dropped, nil
+}
+
+func (q *memoryQueue) peek() ([]byte, uint64, bool) {
+	if len(q.frames) == 0 {
+		return nil, 0, false
+	}
+	return q.frames[0], q.popped, true
+}
+
+func (q *memoryQueue) pop(id uint64) {
+	if len(q.frames) == 0 || id != q.popped {
+		return
+	}
+	q.size -= len(q.frames[0])
+	q.frames[0] = nil
+	q.frames = q.frames[1:]
+	q.popped++
+}
+
+// take removes and returns all frames.
+func (q *memoryQueue) take() [][]byte {
+	frames := q.frames
+	q.frames, q.size = nil, 0
+	q.popped += uint64(len(frames))
+	return frames
+}
+
+// diskQueue stores frames in a file, each after its 4-byte length; read advances through the file, which is truncated
+// once everything is sent and compacted once the sent prefix outgrows the size limit, so the file stays within about
+// twice the limit. Frames left in the file are picked up when it is opened again.
+type diskQueue struct {
+	file   *os.File
+	read   int64
+	write  int64
+	max    int64
+	popped uint64
+}
+
+func openDiskQueue(path string, max int) (*diskQueue, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &diskQueue{file: f, write: info.Size(), max: int64(max)}, nil
+}
+
+func (q *diskQueue) push(frame []byte) (int, error) {
+	record := make([]byte, 4+len(frame))
+	binary.BigEndian.PutUint32(record, uint32(len(frame)))
+	copy(record[4:], frame)
+	if _, err := q.file.WriteAt(record, q.write); err != nil {
+		return 0, err
+	}
+	q.write += int64(len(record))
+
+	dropped := 0
+	for q.write-q.read > q.max {
+		size, ok := q.frameSize()
+		if !ok || q.read+4+size >= q.write {
+			break
+		}
+		q.read += 4 + size
+		q.popped++
+		dropped++
+	}
+	q.compactIfNeeded()
+	return dropped, nil
+}
+
+func (q *diskQueue) frameSize() (int64, bool) {
+	var header [4]byte
+	if q.read+4 > q.write {
+		return 0, false
+	}
+	if _, err := q.file.ReadAt(header[:], q.read); err != nil {
+		return 0, false
+	}
+	size := int64(binary.BigEndian.Uint32(header[:]))
+	if q.read+4+size > q.write {
+		return 0, false
+	}
+	return size, true
+}
+
+func (q *diskQueue) peek() ([]byte, uint64, bool) {
+	size, ok := q.frameSize()
+	if !ok {
+		if q.write > 0 {
+			q.reset()
+		}
+		return nil, 0, false
+	}
+	frame := make([]byte, size)
+	if _, err := q.file.ReadAt(frame, q.read+4); err != nil && err != io.EOF {
+		q.reset()
+		return nil, 0, false
+	}
+	return frame, q.popped, true
+}
+
+func (q *diskQueue) pop(id uint64) {
+	if id != q.popped {
+		return
+	}
+	if size, ok := q.frameSize(); ok {
+		q.read += 4 + size
+		q.popped++
+	}
+	if q.read >= q.write {
+		q.reset()
+		return
+	}
+	q.compactIfNeeded()
+}
+
+// compactIfNeeded moves the unsent frames to the start of the file once the sent prefix is larger than the size limit.
+func (q *diskQueue) compactIfNeeded() {
+	if q.read > q.max {
+		q.compact()
+	}
+}
+
+// compact cuts off the sent prefix, moving the unsent frames to the start of the file.
+func (q *diskQueue) compact() {
+	if q.read == 0 || q.read >= q.write {
+		return
+	}
+	rest := make([]byte, q.write-q.read)
+	if _, err := q.file.ReadAt(rest, q.read); err != nil && err != io.EOF {
+		return
+	}
+	if _, err := q.file.WriteAt(rest, 0); err != nil {
+		return
+	}
+	q.read, q.write = 0, int64(len(rest))
+	_ = q.file.Truncate(q.write)
+}
+
+func (q *diskQueue) empty() bool {
+	return q.read >= q.write
+}
+
+// reset empties the file; a truncated trailing frame is discarded with it.
+func (q *diskQueue) reset() {
+	q.read, q.write = 0, 0
+	_ = q.file.Truncate(0)
+}
+
+// close keeps unsent frames: the sent prefix is cut off so the next open starts at the oldest unsent frame.
+func (q *diskQueue) close() error {
+	q.compact()
+	return q.file.Close()
+}
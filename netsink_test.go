@@ -0,0 +1,196 @@
+package glog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acceptLines accepts one connection on l and sends each line read from it to the returned channel.
+func acceptLines(t *testing.T, l net.Listener) <-chan string {
+	lines := make(chan string, 16)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(lines)
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	return lines
+}
+
+func receive(t *testing.T, lines <-chan string) string {
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for line")
+		return ""
+	}
+}
+
+func TestNetworkWriter_TCPNewlineFraming(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	lines := acceptLines(t, l)
+
+	w, err := NewNetworkWriter("tcp", l.Addr().String())
+	require.NoError(t, err)
+	log := NewWithWriters(w, w, INFO)
+	log.Info("shipped %d", 1)
+	log.Warn("shipped %d", 2)
+
+	assert.Contains(t, receive(t, lines), "INFO shipped 1")
+	assert.Contains(t, receive(t, lines), "WARN shipped 2")
+	assert.NoError(t, w.Close())
+	_, err = w.Write([]byte("late\n"))
+	assert.Equal(t, ErrWriterClosed, err)
+}
+
+func TestNetworkWriter_LengthPrefixFraming(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	frames := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var size uint32
+		_ = binary.Read(conn, binary.BigEndian, &size)
+		frame := make([]byte, size)
+		_, _ = io.ReadFull(conn, frame)
+		frames <- string(frame)
+	}()
+
+	w, err := NewNetworkWriter("tcp", l.Addr().String(), NetworkOptions{Framing: LengthPrefixFraming})
+	require.NoError(t, err)
+	defer w.Close()
+	_, _ = w.Write([]byte("framed line\n"))
+
+	assert.Equal(t, "framed line", receive(t, frames))
+}
+
+func TestNetworkWriter_ReconnectsAndBuffers(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	w, err := NewNetworkWriter("tcp", address, NetworkOptions{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+	require.NoError(t, err)
+	defer w.Close()
+	_, _ = w.Write([]byte("while down\n"))
+	time.Sleep(30 * time.Millisecond)
+
+	l, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer l.Close()
+	lines := acceptLines(t, l)
+
+	assert.Equal(t, "while down", receive(t, lines))
+}
+
+func TestNetworkWriter_MemoryBufferDropsOldest(t *testing.T) {
+	w, err := NewNetworkWriter("tcp", "127.0.0.1:1", NetworkOptions{BufferSize: 10, MinBackoff: time.Hour})
+	require.NoError(t, err)
+
+	_, _ = w.Write([]byte("first\n"))
+	_, _ = w.Write([]byte("second\n"))
+	_, _ = w.Write([]byte("third\n"))
+
+	assert.Equal(t, uint64(2), w.Dropped())
+	assert.NoError(t, w.Close())
+}
+
+func TestNetworkWriter_DiskBufferSurvivesRestart(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "spool")
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	w, err := NewNetworkWriter("tcp", address, NetworkOptions{BufferPath: spool, MinBackoff: time.Hour})
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("spooled 1\n"))
+	_, _ = w.Write([]byte("spooled 2\n"))
+	require.NoError(t, w.Close())
+
+	l, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer l.Close()
+	lines := acceptLines(t, l)
+	w, err = NewNetworkWriter("tcp", address, NetworkOptions{BufferPath: spool})
+	require.NoError(t, err)
+	defer w.Close()
+
+	assert.Equal(t, "spooled 1", receive(t, lines))
+	assert.Equal(t, "spooled 2", receive(t, lines))
+}
+
+func TestDiskQueue_CompactsWhileNeverEmpty(t *testing.T) {
+	q, err := openDiskQueue(filepath.Join(t.TempDir(), "spool"), 1000)
+	require.NoError(t, err)
+	defer q.close()
+
+	_, err = q.push([]byte("pending line\n"))
+	require.NoError(t, err)
+	for i := 0; i < 10000; i++ {
+		_, err = q.push([]byte(fmt.Sprintf("line %d\n", i)))
+		require.NoError(t, err)
+		want := fmt.Sprintf("line %d\n", i-1)
+		if i == 0 {
+			want = "pending line\n"
+		}
+		frame, id, ok := q.peek()
+		require.True(t, ok)
+		require.Equal(t, want, string(frame))
+		q.pop(id)
+	}
+
+	info, err := q.file.Stat()
+	require.NoError(t, err)
+	assert.True(t, info.Size() <= 2*1000+64, "spool is %d bytes", info.Size())
+	frame, _, ok := q.peek()
+	require.True(t, ok)
+	assert.Equal(t, "line 9999\n", string(frame))
+}
+
+func TestNetworkWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	w, err := NewNetworkWriter("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer w.Close()
+	_, _ = w.Write([]byte("datagram\n"))
+
+	buf := make([]byte, 1024)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "datagram\n", string(buf[:n]))
+}
+
+func TestNewNetworkWriter_InvalidNetwork(t *testing.T) {
+	_, err := NewNetworkWriter("sctp", "localhost:1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package glog
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// RecoverOptions controls what happens after a recovered panic has been logged.
+type RecoverOptions struct {
+	// Message prefixes the logged panic value; defaults to "recovered panic".
+	Message string
+	// Repanic re-raises the original panic value after it has been logged.
+	Repanic bool
+	// Fatal logs at FATAL level and exits instead of logging at PANIC level.
+	Fatal bool
+}
+
+// Recover logs a panic in progress with its stack at PANIC level. Must be deferred directly: defer glog.Recover(logger).
+// A nil logger means Default(). Options are optional; by default the panic is swallowed.
+func Recover(logger Logger, opts ...RecoverOptions) {
+	if r := recover(); r != nil {
+		handlePanic(r, logger, opts...)
+	}
+}
+
+// LogPanics logs a panic in progress with the default logger and swallows it. Must be deferred directly: defer glog.LogPanics().
+func LogPanics() {
+	if r := recover(); r != nil {
+		handlePanic(r, Default())
+	}
+}
+
+// Go runs f in a new goroutine guarded by Recover with the default logger.
+func Go(f func(), opts ...RecoverOptions) {
+	GoWith(nil, f, opts...)
+}
+
+// GoWith runs f in a new goroutine guarded by Recover with the given logger (nil means Default()).
+func GoWith(logger Logger, f func(), opts ...RecoverOptions) {
+	go func() {
+		defer Recover(logger, opts...)
+		f()
+	}()
+}
+
+func handlePanic(r interface{}, logger Logger, opts ...RecoverOptions) {
+	if logger == nil {
+		logger = Default()
+	}
+	var opt RecoverOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	message := opt.Message
+	if message == "" {
+		message = "recovered panic"
+	}
+	stack := debug.Stack()
+
+	if opt.Fatal {
+		logger.Fatal("%s: %v\n%s", message, r, stack)
+		return
+	}
+	logPanic(logger, "%s: %v\n%s", message, r, stack)
+	if opt.Repanic {
+		panic(r)
+	}
+}
+
+// logPanic writes a PANIC record to every sink of logger without panicking (see logRecord).
+func logPanic(logger Logger, format string, a ...interface{}) {
+	logRecord(logger, &Record{Time: time.Now(), Level: PANIC, Message: fmt.Sprintf(format, a...)})
+}
@@ -0,0 +1,108 @@
+package glog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover_LogsAndSwallows(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	func() {
+		defer Recover(log)
+		panic("boom")
+	}()
+
+	assert.Contains(t, buf.String(), "PANIC recovered panic: boom")
+	assert.Contains(t, buf.String(), "goroutine")
+}
+
+func TestRecover_LogsToEveryCompositeSink(t *testing.T) {
+	var file, console bytes.Buffer
+	log := Named(Composite(NewWithWriters(&file, &file, INFO), NewWithWriters(&console, &console, WARN)), "worker")
+
+	var r interface{}
+	func() {
+		defer func() {
+			r = recover()
+		}()
+		defer Recover(log, RecoverOptions{Repanic: true})
+		panic("boom")
+	}()
+
+	assert.Equal(t, "boom", r)
+	assert.Contains(t, file.String(), "PANIC recovered panic: boom")
+	assert.Contains(t, console.String(), "PANIC recovered panic: boom")
+	assert.Equal(t, 1, strings.Count(console.String(), "logger=worker"))
+}
+
+func TestRecover_Repanic(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+	cause := fmt.Errorf("original")
+
+	var r interface{}
+	func() {
+		defer func() {
+			r = recover()
+		}()
+		defer Recover(log, RecoverOptions{Message: "worker failed", Repanic: true})
+		panic(cause)
+	}()
+
+	assert.Equal(t, cause, r)
+	assert.Contains(t, buf.String(), "worker failed: original")
+}
+
+func TestRecover_Fatal(t *testing.T) {
+	_logger := Create(TRACE)
+	logger := _logger.(logger)
+	var fatal string
+	logger.fatalf = func(format string, a ...interface{}) {
+		fatal = fmt.Sprintf(format, a...)
+	}
+
+	func() {
+		defer Recover(logger, RecoverOptions{Fatal: true})
+		panic("fatal boom")
+	}()
+
+	assert.Contains(t, fatal, "FATAL recovered panic: fatal boom")
+}
+
+func TestLogPanics_UsesDefault(t *testing.T) {
+	var out, err bytes.Buffer
+	SetWriters(&out, &err, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	func() {
+		defer LogPanics()
+		panic("default boom")
+	}()
+
+	assert.Contains(t, err.String(), "recovered panic: default boom")
+}
+
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+func TestGoWith_RecoversGoroutinePanic(t *testing.T) {
+	out := make(chanWriter, 1)
+	log := NewWithWriters(out, out, INFO)
+
+	GoWith(log, func() {
+		panic("goroutine boom")
+	})
+
+	assert.Contains(t, <-out, "recovered panic: goroutine boom")
+}
@@ -0,0 +1,197 @@
+package glog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedMask replaces redacted values unless a rule says otherwise.
+const RedactedMask = "[REDACTED]"
+
+// Redactor is implemented by types that know how to log themselves without secrets.
+// Redact returns the value to log in place of the receiver, for message arguments and field values alike.
+type Redactor interface {
+	Redact() interface{}
+}
+
+// RedactionRule rewrites matches of Pattern in formatted messages and field values.
+type RedactionRule struct {
+	Pattern *regexp.Regexp
+	// Replacement is expanded like regexp.ReplaceAllString ($1 refers to a group); empty means RedactedMask.
+	Replacement string
+	// Match, if set, rewrites only the matches it returns true for, e.g. numbers that pass a checksum.
+	Match func(match string) bool
+}
+
+// BearerTokenRule masks "Bearer <token>" credentials.
+var BearerTokenRule = RedactionRule{
+	Pattern:     regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+	Replacement: "${1}" + RedactedMask,
+}
+
+// CreditCardRule masks card numbers: runs of 13 to 19 digits, optionally separated by spaces or dashes, that pass the
+// Luhn check, so most IDs and timestamps of that length are left alone.
+var CreditCardRule = RedactionRule{
+	Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+	Match:   luhnValid,
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum; other characters are ignored.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// PasswordRule masks values of password, secret, token and API key assignments such as "password=x" or "Password:x".
+var PasswordRule = RedactionRule{
+	Pattern:     regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api[_-]?key)(\s*[=:]\s*)("[^"]*"|[^\s,;&}\]]+)`),
+	Replacement: "${1}${2}" + RedactedMask,
+}
+
+// Redaction configures Redact.
+type Redaction struct {
+	// Rules are applied in order to every formatted message and to field values.
+	Rules []RedactionRule
+	// DenyFields lists field keys (case-insensitive) whose values are always replaced with RedactedMask.
+	DenyFields []string
+}
+
+// DefaultRedaction masks bearer tokens, card numbers and password-like assignments, and common secret field names.
+var DefaultRedaction = Redaction{
+	Rules:      []RedactionRule{BearerTokenRule, CreditCardRule, PasswordRule},
+	DenyFields: []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey"},
+}
+
+// Redact returns a Logger that removes secrets before anything reaches logger. Arguments and field values implementing
+// Redactor are replaced by their Redact() result, the formatted message and field values are rewritten by the rules, and
+// denied fields are masked. Wrap a Composite to redact for all of its loggers. Errors returned by Error and ErrorErr are
+// not redacted.
+func Redact(logger Logger, redaction Redaction) Logger {
+	deny := make(map[string]bool, len(redaction.DenyFields))
+	for _, name := range redaction.DenyFields {
+		deny[strings.ToLower(name)] = true
+	}
+	return newDerived(redactor{next: logger, rules: redaction.Rules, deny: deny}, logger)
+}
+
+// SetRedaction wraps the default logger with Redact, outside any SetFilter filter. The redaction stays in place when the
+// default logger is replaced (Configure, WatchConfig, SetWriters, ToFile, ...); calling SetRedaction again replaces it,
+// and an empty Redaction removes it.
+func SetRedaction(redaction Redaction) {
+	updateDefault(func(h *defaultHolder) {
+		h.redaction = nil
+		if len(redaction.Rules) > 0 || len(redaction.DenyFields) > 0 {
+			h.redaction = &redaction
+		}
+	})
+}
+
+type redactor struct {
+	next  Logger
+	rules []RedactionRule
+	deny  map[string]bool
+}
+
+func (r redactor) IsEnabled(logLevel LogLevel) bool {
+	return r.next.IsEnabled(logLevel)
+}
+
+func (r redactor) logFields(logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	if logLevel != PANIC && logLevel != FATAL && !r.next.IsEnabled(logLevel) {
+		return
+	}
+	args := make([]interface{}, len(a))
+	for i, arg := range a {
+		args[i] = redactValue(arg)
+	}
+	message := r.redactString(fmt.Sprintf(format, args...))
+	logWithFields(r.next, logLevel, r.redactFields(fields), "%s", message)
+}
+
+func (r redactor) logRecord(rec *Record) {
+	redacted := *rec
+	redacted.Message = r.redactString(rec.Message)
+	redacted.Fields = r.redactFields(rec.Fields)
+	logRecord(r.next, &redacted)
+}
+
+func (r redactor) redactFields(fields []Field) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+	redacted := make([]Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = f
+		if r.deny[strings.ToLower(f.Key)] {
+			redacted[i] = Field{Key: f.Key, Value: RedactedMask}
+			continue
+		}
+		switch f.kind {
+		case objectField:
+			redacted[i] = Object(f.Key, Fields(r.redactFields(f.objectFields())))
+			continue
+		case plainField:
+		default:
+			if s := f.text(); r.redactString(s) != s {
+				redacted[i] = Field{Key: f.Key, Value: r.redactString(s)}
+			}
+			continue
+		}
+		value := redactValue(f.Value)
+		if s := fmt.Sprint(value); r.redactString(s) != s {
+			value = r.redactString(s)
+		}
+		redacted[i].Value = value
+	}
+	return redacted
+}
+
+func (r redactor) redactString(s string) string {
+	for _, rule := range r.rules {
+		if rule.Pattern == nil {
+			continue
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = RedactedMask
+		}
+		if rule.Match == nil {
+			s = rule.Pattern.ReplaceAllString(s, replacement)
+			continue
+		}
+		pattern, match := rule.Pattern, rule.Match
+		s = pattern.ReplaceAllStringFunc(s, func(m string) string {
+			if !match(m) {
+				return m
+			}
+			return pattern.ReplaceAllString(m, replacement)
+		})
+	}
+	return s
+}
+
+// redactValue returns v.Redact() for Redactors, looking through Lazy values.
+func redactValue(v interface{}) interface{} {
+	if lazy, ok := v.(*LazyValue); ok {
+		v = lazy.Value()
+	}
+	if redactor, ok := v.(Redactor); ok {
+		return redactor.Redact()
+	}
+	return v
+}
@@ -0,0 +1,145 @@
+package glog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type credentials struct {
+	User     string
+	Password string
+}
+
+func (c credentials) Redact() interface{} {
+	return credentials{User: c.User, Password: RedactedMask}
+}
+
+func TestRedact_Rules(t *testing.T) {
+	var buf bytes.Buffer
+	log := Redact(NewWithWriters(&buf, &buf, INFO), DefaultRedaction)
+
+	log.Info("auth header: Authorization: Bearer abc.DEF-123_x=")
+	log.Info("card %s charged", "4111 1111 1111 1111")
+	log.Info("order 1718287412345678901 shipped at %d", int64(1767225600123456789))
+	log.Info("config %+v", struct{ User, Password string }{"bob", "hunter2"})
+	log.Info("dsn user=bob password=\"s3 cret\" host=db")
+
+	out := buf.String()
+	assert.Contains(t, out, "Bearer [REDACTED]")
+	assert.NotContains(t, out, "abc.DEF")
+	assert.Contains(t, out, "card [REDACTED] charged")
+	assert.Contains(t, out, "order 1718287412345678901 shipped at 1767225600123456789")
+	assert.Contains(t, out, "config {User:bob Password:[REDACTED]}")
+	assert.Contains(t, out, "dsn user=bob password=[REDACTED] host=db")
+}
+
+func TestLuhnValid(t *testing.T) {
+	assert.True(t, luhnValid("4111-1111-1111-1111"))
+	assert.True(t, luhnValid("5500 0000 0000 0004"))
+	assert.False(t, luhnValid("4111 1111 1111 1112"))
+	assert.False(t, luhnValid("1718287412345678901"))
+}
+
+func TestRedact_RedactorArgument(t *testing.T) {
+	var buf bytes.Buffer
+	log := Redact(NewWithWriters(&buf, &buf, INFO), Redaction{})
+
+	log.Info("login %v", credentials{User: "alice", Password: "pa55"})
+	log.Info("lazy %v", Lazy(func() interface{} { return credentials{User: "carol", Password: "x"} }))
+
+	assert.Contains(t, buf.String(), "login {alice [REDACTED]}")
+	assert.Contains(t, buf.String(), "lazy {carol [REDACTED]}")
+	assert.NotContains(t, buf.String(), "pa55")
+}
+
+func TestRedact_Fields(t *testing.T) {
+	var buf bytes.Buffer
+	w := Encoded(&buf, LogfmtEncoder{})
+	log := Redact(NewWithWriters(w, w, INFO), DefaultRedaction)
+
+	logWithFields(log, INFO, []Field{
+		{Key: "Token", Value: "abc"},
+		{Key: "header", Value: "Bearer xyz"},
+		{Key: "user", Value: credentials{User: "dave", Password: "pw"}},
+		{Key: "count", Value: 3},
+	}, "request")
+
+	out := buf.String()
+	assert.Contains(t, out, "Token=[REDACTED]")
+	assert.Contains(t, out, `header="Bearer [REDACTED]"`)
+	assert.Contains(t, out, `user="{dave [REDACTED]}"`)
+	assert.Contains(t, out, "count=3")
+}
+
+func TestRedact_BeforeCompositeSinks(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	log := Redact(Composite(NewWithWriters(&buf1, &buf1, INFO), NewWithWriters(&buf2, &buf2, INFO)), DefaultRedaction)
+
+	err := log.Error("token=abc123 rejected")
+
+	assert.Contains(t, buf1.String(), "token=[REDACTED] rejected")
+	assert.Contains(t, buf2.String(), "token=[REDACTED] rejected")
+	assert.EqualError(t, err, "token=abc123 rejected")
+}
+
+func TestRedact_DisabledLevelAndPanic(t *testing.T) {
+	var buf bytes.Buffer
+	log := Redact(NewWithWriters(&buf, &buf, INFO), DefaultRedaction)
+
+	log.Debug("password=x")
+	assert.False(t, log.IsDebug())
+	assert.Empty(t, buf.String())
+
+	assert.PanicsWithValue(t, "PANIC secret=[REDACTED]", func() {
+		log.Panic("secret=%s", "x")
+	})
+}
+
+func TestRedact_PassesSetLevel(t *testing.T) {
+	log := Redact(NewWithWriters(&bytes.Buffer{}, &bytes.Buffer{}, INFO), DefaultRedaction)
+
+	log.(LevelSetter).SetLevel(DEBUG)
+
+	assert.True(t, log.IsDebug())
+}
+
+func TestSetRedaction_Default(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	SetRedaction(DefaultRedaction)
+	defer SetRedaction(Redaction{})
+	Info("api_key: k-123")
+
+	assert.Contains(t, out.String(), "api_key: [REDACTED]")
+}
+
+func TestSetRedaction_SurvivesReplacedDefault(t *testing.T) {
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+	SetRedaction(DefaultRedaction)
+
+	var out bytes.Buffer
+	SetWriters(&out, &out, INFO)
+	Info("api_key: k-123")
+	assert.Contains(t, out.String(), "api_key: [REDACTED]")
+
+	fpath := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, Configure(Config{Sinks: []SinkConfig{{Type: "file", Path: fpath}}}))
+	Info("token=abc")
+	content, err := ioutil.ReadFile(fpath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "token=[REDACTED]")
+
+	SetRedaction(Redaction{})
+	out.Reset()
+	SetWriters(&out, &out, INFO)
+	Info("token=abc")
+	assert.Contains(t, out.String(), "token=abc")
+}
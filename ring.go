@@ -0,0 +1,142 @@
+package glog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RingBuffer keeps the last records logged to it in memory, e.g. to see the DEBUG lines behind an error without
+// writing them to the console. It is a writer for loggers (records keep their level and fields) and an http.Handler
+// that serves the records.
+type RingBuffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewRingBuffer returns a buffer keeping the last size records (at least 1).
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &RingBuffer{records: make([]Record, size)}
+}
+
+// Logger returns a logger writing records of every level to the buffer; combine it with the console logger, e.g.
+// Composite(console, ring.Logger()).
+func (b *RingBuffer) Logger() Logger {
+	return NewWithWriters(b, b, TRACE)
+}
+
+// Write stores p, one line, as an INFO record.
+func (b *RingBuffer) Write(p []byte) (int, error) {
+	b.add(Record{Time: time.Now(), Level: INFO, Message: strings.TrimSuffix(string(p), "\n")})
+	return len(p), nil
+}
+
+// Printf stores an INFO record; loggers call writeRecord with the real level.
+func (b *RingBuffer) Printf(format string, a ...interface{}) {
+	b.add(Record{Time: time.Now(), Level: INFO, Message: fmt.Sprintf(format, a...)})
+}
+
+func (b *RingBuffer) writeRecord(r *Record, _ *writeErrors) {
+	record := *r
+	record.Fields = append([]Field(nil), r.Fields...)
+	b.add(record)
+}
+
+func (b *RingBuffer) add(r Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records[b.next] = r
+	if b.next++; b.next == len(b.records) {
+		b.next, b.full = 0, true
+	}
+}
+
+// Records returns the buffered records, oldest first.
+func (b *RingBuffer) Records() []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]Record(nil), b.records[:b.next]...)
+	}
+	records := make([]Record, 0, len(b.records))
+	records = append(records, b.records[b.next:]...)
+	return append(records, b.records[:b.next]...)
+}
+
+// Clear discards the buffered records.
+func (b *RingBuffer) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := range b.records {
+		b.records[i] = Record{}
+	}
+	b.next, b.full = 0, false
+}
+
+// ServeHTTP writes the buffered records, oldest first, in the text format. Query parameters: format ("text", "logfmt"
+// or "json" for NDJSON), level (minimum level, e.g. "warn"), limit (only the newest N) and download (any value; served
+// as an attachment).
+func (b *RingBuffer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var enc Encoder = TextEncoder{}
+	contentType, extension := "text/plain; charset=utf-8", "log"
+	switch query.Get("format") {
+	case "", "text":
+	case "logfmt":
+		enc = LogfmtEncoder{}
+	case "json":
+		enc = JSONEncoder{}
+		contentType, extension = "application/x-ndjson", "ndjson"
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q", query.Get("format")), http.StatusBadRequest)
+		return
+	}
+	minLevel := 0
+	if s := query.Get("level"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		minLevel = levelIndex(level)
+	}
+	limit := -1
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid limit %q", s), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	records := b.Records()
+	selected := records[:0]
+	for _, record := range records {
+		if levelIndex(record.Level) >= minLevel {
+			selected = append(selected, record)
+		}
+	}
+	if limit >= 0 && len(selected) > limit {
+		selected = selected[len(selected)-limit:]
+	}
+
+	var buf bytes.Buffer
+	for i := range selected {
+		enc.Encode(&buf, &selected[i])
+	}
+	w.Header().Set("Content-Type", contentType)
+	if _, ok := query["download"]; ok {
+		w.Header().Set("Content-Disposition", `attachment; filename="log-`+time.Now().Format("20060102-150405")+"."+extension+`"`)
+	}
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,83 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingBuffer_KeepsLastRecordsAtAllLevels(t *testing.T) {
+	ring := NewRingBuffer(3)
+	var console bytes.Buffer
+	log := Composite(NewWithWriters(&console, &console, WARN), ring.Logger())
+
+	log.Debug("one")
+	log.Trace("two")
+	logWithFields(log, INFO, []Field{{Key: "user", Value: "ann"}}, "three")
+	log.Warn("four")
+
+	records := ring.Records()
+	require.Len(t, records, 3)
+	assert.Equal(t, TRACE, records[0].Level)
+	assert.Equal(t, "two", records[0].Message)
+	assert.Equal(t, []Field{{Key: "user", Value: "ann"}}, records[1].Fields)
+	assert.Equal(t, WARN, records[2].Level)
+	assert.Equal(t, "four", records[2].Message)
+	assert.NotContains(t, console.String(), "one")
+	assert.Contains(t, console.String(), "four")
+
+	ring.Clear()
+	assert.Empty(t, ring.Records())
+}
+
+func TestRingBuffer_WriteStoresLines(t *testing.T) {
+	ring := NewRingBuffer(0)
+	_, _ = ring.Write([]byte("first\n"))
+	_, _ = ring.Write([]byte("second\n"))
+
+	records := ring.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, INFO, records[0].Level)
+	assert.Equal(t, "second", records[0].Message)
+}
+
+func TestRingBuffer_ServeHTTP(t *testing.T) {
+	ring := NewRingBuffer(10)
+	log := ring.Logger()
+	log.Debug("starting")
+	log.Warn("slow %d", 1)
+	log.Warn("slow %d", 2)
+	_ = log.Error("failed")
+
+	rec := httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "DEBUG starting")
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+
+	rec = httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?format=json&level=warn&limit=2&download=1", nil))
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+	lines = strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "slow 2", entry["msg"])
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "error", entry["level"])
+
+	for _, query := range []string{"format=xml", "level=loud", "limit=-1"} {
+		rec = httptest.NewRecorder()
+		ring.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?"+query, nil))
+		assert.Equal(t, 400, rec.Code, query)
+	}
+}
@@ -0,0 +1,127 @@
+package glog
+
+import (
+	"context"
+	"time"
+)
+
+// CallInfo describes an RPC for call logging.
+type CallInfo struct {
+	// Method is the full method name, e.g. "/pkg.Service/Get".
+	Method string
+	// Peer is the remote address; empty leaves the peer field out.
+	Peer string
+}
+
+// UnaryCall runs a unary RPC; a gRPC UnaryHandler converts to it directly.
+type UnaryCall func(ctx context.Context, req interface{}) (interface{}, error)
+
+// StreamCall runs a streaming RPC until it ends.
+type StreamCall func(ctx context.Context) error
+
+// CallOptions configures LogUnary and LogStream.
+type CallOptions struct {
+	// Code returns the status code name for a call's error; default "OK" for nil and "Unknown" otherwise.
+	// For gRPC use func(err error) string { return status.Code(err).String() }.
+	Code func(err error) string
+	// Level returns the level for a finished call; default INFO when err is nil and ERROR otherwise.
+	Level func(code string, err error) LogLevel
+}
+
+// LogUnary returns a unary call hook that logs each call's method, status code, duration and peer, and puts a logger
+// carrying the method in the call's context for FromContext. A nil logger means the default logger. Adapt it to a gRPC
+// server interceptor with:
+//
+//	logCall := glog.LogUnary(log)
+//	grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (interface{}, error) {
+//		return logCall(ctx, glog.CallInfo{Method: info.FullMethod, Peer: peerAddr(ctx)}, req, glog.UnaryCall(h))
+//	})
+//
+//	func peerAddr(ctx context.Context) string {
+//		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+//			return p.Addr.String()
+//		}
+//		return ""
+//	}
+func LogUnary(logger Logger, opts ...CallOptions) func(ctx context.Context, info CallInfo, req interface{}, call UnaryCall) (interface{}, error) {
+	opt := callOptions(opts)
+	return func(ctx context.Context, info CallInfo, req interface{}, call UnaryCall) (interface{}, error) {
+		start := time.Now()
+		callLogger, ctx := startCall(ctx, logger, info)
+		resp, err := call(ctx, req)
+		logCall(callLogger, opt, info, "rpc call", time.Since(start), err)
+		return resp, err
+	}
+}
+
+// LogStream is LogUnary for streaming calls; the line is written when the stream ends. A gRPC StreamHandler does not
+// convert to StreamCall: wrap the ServerStream so the handler sees the context carrying the call logger:
+//
+//	logStream := glog.LogStream(log)
+//	grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+//		return logStream(ss.Context(), glog.CallInfo{Method: info.FullMethod, Peer: peerAddr(ss.Context())}, func(ctx context.Context) error {
+//			return h(srv, &loggedStream{ServerStream: ss, ctx: ctx})
+//		})
+//	})
+//
+//	type loggedStream struct {
+//		grpc.ServerStream
+//		ctx context.Context
+//	}
+//
+//	func (s *loggedStream) Context() context.Context { return s.ctx }
+func LogStream(logger Logger, opts ...CallOptions) func(ctx context.Context, info CallInfo, call StreamCall) error {
+	opt := callOptions(opts)
+	return func(ctx context.Context, info CallInfo, call StreamCall) error {
+		start := time.Now()
+		callLogger, ctx := startCall(ctx, logger, info)
+		err := call(ctx)
+		logCall(callLogger, opt, info, "rpc stream", time.Since(start), err)
+		return err
+	}
+}
+
+func callOptions(opts []CallOptions) CallOptions {
+	var opt CallOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Code == nil {
+		opt.Code = func(err error) string {
+			if err == nil {
+				return "OK"
+			}
+			return "Unknown"
+		}
+	}
+	if opt.Level == nil {
+		opt.Level = func(_ string, err error) LogLevel {
+			if err == nil {
+				return INFO
+			}
+			return ERROR
+		}
+	}
+	return opt
+}
+
+// startCall returns the call's logger and a context carrying it.
+func startCall(ctx context.Context, logger Logger, info CallInfo) (Logger, context.Context) {
+	if logger == nil {
+		logger = Default()
+	}
+	callLogger := With(logger, Field{Key: "method", Value: info.Method})
+	return callLogger, NewContext(ctx, callLogger)
+}
+
+func logCall(logger Logger, opt CallOptions, info CallInfo, message string, duration time.Duration, err error) {
+	code := opt.Code(err)
+	fields := []Field{{Key: "code", Value: code}, {Key: "duration", Value: duration}}
+	if info.Peer != "" {
+		fields = append(fields, Field{Key: "peer", Value: info.Peer})
+	}
+	if err != nil {
+		fields = append(fields, Field{Key: ErrorKey, Value: err.Error()})
+	}
+	logWithFields(logger, opt.Level(code, err), fields, "%s", message)
+}
@@ -0,0 +1,64 @@
+package glog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogUnary(t *testing.T) {
+	var buf bytes.Buffer
+	logCall := LogUnary(NewWithWriters(&buf, &buf, INFO))
+	info := CallInfo{Method: "/users.Users/Get", Peer: "10.0.0.1:5000"}
+
+	resp, err := logCall(context.Background(), info, "req", func(ctx context.Context, req interface{}) (interface{}, error) {
+		FromContext(ctx).Info("handling %v", req)
+		return "resp", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+	assert.Contains(t, buf.String(), "INFO handling req method=/users.Users/Get\n")
+	assert.Regexp(t, `INFO rpc call method=/users.Users/Get code=OK duration=\S+ peer=10\.0\.0\.1:5000\n`, buf.String())
+}
+
+func TestLogUnary_ErrorCodeAndLevel(t *testing.T) {
+	var buf bytes.Buffer
+	notFound := errors.New("no such user")
+	logCall := LogUnary(NewWithWriters(&buf, &buf, INFO), CallOptions{
+		Code: func(err error) string {
+			if err == notFound {
+				return "NotFound"
+			}
+			return "OK"
+		},
+		Level: func(code string, err error) LogLevel {
+			if code == "NotFound" {
+				return WARN
+			}
+			return INFO
+		},
+	})
+
+	_, err := logCall(context.Background(), CallInfo{Method: "/users.Users/Get"}, nil, func(context.Context, interface{}) (interface{}, error) {
+		return nil, notFound
+	})
+
+	assert.Equal(t, notFound, err)
+	assert.Regexp(t, `WARN rpc call method=/users.Users/Get code=NotFound duration=\S+ error="no such user"\n`, buf.String())
+}
+
+func TestLogStream(t *testing.T) {
+	var buf bytes.Buffer
+	logStream := LogStream(NewWithWriters(&buf, &buf, INFO))
+
+	err := logStream(context.Background(), CallInfo{Method: "/feed.Feed/Watch"}, func(ctx context.Context) error {
+		return errors.New("stream reset")
+	})
+
+	assert.Error(t, err)
+	assert.Regexp(t, `ERROR rpc stream method=/feed.Feed/Watch code=Unknown duration=\S+ error="stream reset"\n`, buf.String())
+}
@@ -0,0 +1,94 @@
+package glog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+)
+
+// Writer returns an io.WriteCloser that logs each line written to it at logLevel, for libraries that take an io.Writer.
+// A partial line is kept until its newline arrives or Close is called, and logged in 64 KiB pieces if it grows beyond
+// that; empty lines are skipped. A nil logger means the default logger at the time of each line.
+func Writer(logger Logger, logLevel LogLevel) io.WriteCloser {
+	return &lineWriter{logger: logger, logLevel: logLevel}
+}
+
+// StdLogger returns a *log.Logger that logs each message at logLevel through logger (nil means the default logger).
+// It adds no prefix or timestamp of its own.
+func StdLogger(logger Logger, logLevel LogLevel) *log.Logger {
+	return log.New(Writer(logger, logLevel), "", 0)
+}
+
+// RedirectStdLog sends the standard library's package-level logger (log.Print etc.) to the default logger at logLevel.
+// The returned function restores the previous output, prefix and flags.
+func RedirectStdLog(logLevel LogLevel) (restore func()) {
+	out, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	log.SetOutput(Writer(nil, logLevel))
+	log.SetPrefix("")
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(out)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}
+}
+
+// maxPartialLine bounds the memory a Writer holds for a line without a newline, e.g. progress output.
+const maxPartialLine = 64 << 10
+
+type lineWriter struct {
+	mu       sync.Mutex
+	logger   Logger
+	logLevel LogLevel
+	partial  []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := p
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if len(w.partial) > 0 {
+			w.partial = append(w.partial, data[:i]...)
+			w.emit(w.partial)
+			w.partial = w.partial[:0]
+		} else {
+			w.emit(data[:i])
+		}
+		data = data[i+1:]
+	}
+	w.partial = append(w.partial, data...)
+	for len(w.partial) >= maxPartialLine {
+		w.emit(w.partial[:maxPartialLine])
+		w.partial = append(w.partial[:0], w.partial[maxPartialLine:]...)
+	}
+	return len(p), nil
+}
+
+// Close logs a pending partial line.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.emit(w.partial)
+	w.partial = nil
+	return nil
+}
+
+func (w *lineWriter) emit(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) == 0 {
+		return
+	}
+	logger := w.logger
+	if logger == nil {
+		logger = Default()
+	}
+	logger.Log(w.logLevel, "%s", line)
+}
@@ -0,0 +1,76 @@
+package glog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter_SplitsLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := Writer(NewWithWriters(&buf, &buf, INFO), WARN)
+
+	_, _ = w.Write([]byte("first\r\nsec"))
+	_, _ = w.Write([]byte("ond\n\nthi"))
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "WARN first")
+	assert.NotContains(t, lines[0], "\r")
+	assert.Contains(t, lines[1], "WARN second")
+	assert.Contains(t, lines[2], "WARN thi")
+}
+
+func TestWriter_BoundsPartialLine(t *testing.T) {
+	var buf bytes.Buffer
+	w := Writer(NewWithWriters(&buf, &buf, INFO), INFO)
+
+	chunk := bytes.Repeat([]byte("."), 1024)
+	for i := 0; i < 100; i++ {
+		_, _ = w.Write(chunk)
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Equal(t, maxPartialLine, strings.Count(buf.String(), "."))
+	assert.True(t, len(w.(*lineWriter).partial) < maxPartialLine)
+
+	assert.NoError(t, w.Close())
+	assert.Equal(t, 100*1024, strings.Count(buf.String(), "."))
+}
+
+func TestWriter_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	w := Writer(NewWithWriters(&buf, &buf, INFO), DEBUG)
+
+	_, _ = w.Write([]byte("hidden\n"))
+	assert.Empty(t, buf.String())
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	std := StdLogger(NewWithWriters(&buf, &buf, INFO), ERROR)
+
+	std.Printf("third-party %d%%", 50)
+
+	assert.Regexp(t, `^\d{4}/\d\d/\d\d \d\d:\d\d:\d\d ERROR third-party 50%\n$`, buf.String())
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	previous := log.Writer()
+	restore := RedirectStdLog(INFO)
+	log.Print("from std log")
+	restore()
+
+	assert.Contains(t, out.String(), "INFO from std log")
+	assert.Equal(t, previous, log.Writer())
+	assert.Equal(t, log.LstdFlags, log.Flags())
+}
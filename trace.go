@@ -0,0 +1,88 @@
+package glog
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TraceIDKey and SpanIDKey are the field keys for W3C trace context IDs.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// TraceContext holds the IDs of a W3C traceparent header.
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// Fields returns the trace_id and span_id fields for the IDs.
+func (tc TraceContext) Fields() []Field {
+	return []Field{{Key: TraceIDKey, Value: tc.TraceID}, {Key: SpanIDKey, Value: tc.SpanID}}
+}
+
+// ParseTraceparent parses a W3C traceparent header ("00-<trace-id>-<parent-id>-<flags>"). It reports false for malformed
+// headers and all-zero IDs. Later versions are accepted as long as they start with the version 00 fields.
+func ParseTraceparent(header string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || version == "00" && len(parts) != 4 ||
+		!isLowerHex(traceID, 32) || !isLowerHex(spanID, 16) || !isLowerHex(flags, 2) ||
+		strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return TraceContext{}, false
+	}
+	return TraceContext{TraceID: traceID, SpanID: spanID, Sampled: fromHex(flags[1])&1 == 1}, true
+}
+
+// TraceFromRequest parses the request's traceparent header.
+func TraceFromRequest(r *http.Request) (TraceContext, bool) {
+	return ParseTraceparent(r.Header.Get("traceparent"))
+}
+
+// WithTrace returns a child of logger carrying the trace_id and span_id of the request's traceparent header, or logger
+// itself if the header is missing or invalid.
+func WithTrace(logger Logger, r *http.Request) Logger {
+	if tc, ok := TraceFromRequest(r); ok {
+		return With(logger, tc.Fields()...)
+	}
+	return logger
+}
+
+// TraceMiddleware returns middleware that places a logger correlated with the request's traceparent in the request
+// context, so FromContext(r.Context()) in handlers logs trace_id and span_id. A nil logger means the logger already in
+// the request context (see HTTPMiddleware), or the default logger. HTTPMiddleware adds the trace IDs by itself.
+func TraceMiddleware(logger Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			base := logger
+			if base == nil {
+				base = FromContext(r.Context())
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), WithTrace(base, r))))
+		})
+	}
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func fromHex(c byte) byte {
+	if c >= 'a' {
+		return c - 'a' + 10
+	}
+	return c - '0'
+}
@@ -0,0 +1,216 @@
+package glog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorKey is the field key used by Err.
+const ErrorKey = "error"
+
+type fieldKind uint8
+
+const (
+	plainField fieldKind = iota // Value as given: Field literals, Any, Err
+	stringField
+	intField
+	boolField
+	floatField
+	durationField
+	timeField
+	objectField
+)
+
+// ObjectMarshaler is a value that describes itself as fields, for Object.
+type ObjectMarshaler interface {
+	LogFields() []Field
+}
+
+// Fields is an ObjectMarshaler of fixed fields, e.g. Object("user", Fields{String("id", id), Int("age", age)}).
+type Fields []Field
+
+// LogFields implements ObjectMarshaler.
+func (f Fields) LogFields() []Field {
+	return f
+}
+
+// String returns a string field.
+func String(key string, value string) Field {
+	return Field{Key: key, kind: stringField, str: value}
+}
+
+// Int returns an integer field.
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: intField, num: int64(value)}
+}
+
+// Int64 returns an integer field.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, kind: intField, num: value}
+}
+
+// Float64 returns a floating-point field.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: floatField, num: int64(math.Float64bits(value))}
+}
+
+// Bool returns a boolean field.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: boolField}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Duration returns a duration field, written like "1.5s".
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: durationField, num: int64(value)}
+}
+
+// Time returns a time field, written in RFC 3339 format with nanoseconds.
+func Time(key string, value time.Time) Field {
+	if n := value.UnixNano(); time.Unix(0, n).Equal(value) {
+		return Field{Key: key, kind: timeField, num: n, loc: value.Location()}
+	}
+	// Outside the range of UnixNano (years 1678-2262).
+	return Field{Key: key, Value: value}
+}
+
+// Err returns an ErrorKey field with err's message.
+func Err(err error) Field {
+	return Field{Key: ErrorKey, Value: err}
+}
+
+// Object returns a field holding value's fields: a nested object in JSON, key.name=value pairs in text and logfmt.
+// LogFields is called only when the record is written.
+func Object(key string, value ObjectMarshaler) Field {
+	return Field{Key: key, Value: value, kind: objectField}
+}
+
+// Any returns a typed field for common types (strings, integers, floats, booleans, durations, times, errors and
+// ObjectMarshalers) and a field with value as is otherwise.
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case int32:
+		return Int64(key, int64(v))
+	case float64:
+		return Float64(key, v)
+	case float32:
+		return Float64(key, float64(v))
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case ObjectMarshaler:
+		return Object(key, v)
+	}
+	return Field{Key: key, Value: value}
+}
+
+// Interface returns the field's value, boxing typed values.
+func (f Field) Interface() interface{} {
+	switch f.kind {
+	case stringField:
+		return f.str
+	case intField:
+		return f.num
+	case boolField:
+		return f.num != 0
+	case floatField:
+		return f.float()
+	case durationField:
+		return time.Duration(f.num)
+	case timeField:
+		return f.time()
+	}
+	return f.Value
+}
+
+func (f Field) float() float64 {
+	return math.Float64frombits(uint64(f.num))
+}
+
+func (f Field) time() time.Time {
+	return time.Unix(0, f.num).In(f.loc)
+}
+
+func (f Field) objectFields() []Field {
+	if marshaler, ok := f.Value.(ObjectMarshaler); ok {
+		return marshaler.LogFields()
+	}
+	return nil
+}
+
+// text returns the printed value, as filters and redaction see it; objects print as "{name=value ...}".
+func (f Field) text() string {
+	switch f.kind {
+	case stringField:
+		return f.str
+	case intField:
+		return strconv.FormatInt(f.num, 10)
+	case boolField:
+		return strconv.FormatBool(f.num != 0)
+	case floatField:
+		return strconv.FormatFloat(f.float(), 'g', -1, 64)
+	case durationField:
+		return time.Duration(f.num).String()
+	case timeField:
+		return f.time().Format(time.RFC3339Nano)
+	case objectField:
+		var buf bytes.Buffer
+		writeFields(&buf, "", f.objectFields(), nil)
+		return "{" + strings.TrimPrefix(buf.String(), " ") + "}"
+	}
+	return fmt.Sprint(f.Value)
+}
+
+// splitFields separates Field arguments at the end of a, as in Log(INFO, "user %s", name, String("role", role)).
+func splitFields(a []interface{}) ([]Field, []interface{}) {
+	i := len(a)
+	for i > 0 {
+		if _, ok := a[i-1].(Field); !ok {
+			break
+		}
+		i--
+	}
+	if i == len(a) {
+		return nil, a
+	}
+	fields := make([]Field, len(a)-i)
+	for j := range fields {
+		fields[j] = a[i+j].(Field)
+	}
+	return fields, a[:i]
+}
+
+// messageFormat returns a format printing msg as is; msg is passed as an argument only if it contains '%'.
+func messageFormat(msg string) (string, []interface{}) {
+	if strings.IndexByte(msg, '%') < 0 {
+		return msg, nil
+	}
+	return "%s", []interface{}{msg}
+}
+
+// fieldsError returns the error Errorw returns: msg, wrapping the error of the first Err field if any.
+func fieldsError(msg string, fields []Field) error {
+	for _, f := range fields {
+		if err, ok := f.Value.(error); ok && f.kind == plainField {
+			return wrapError(err, "%s", msg)
+		}
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,234 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	id   int
+	name string
+}
+
+func (u user) LogFields() []Field {
+	return []Field{Int("id", u.id), String("name", u.name)}
+}
+
+var typedAt = time.Date(2026, 3, 1, 12, 30, 0, 500, time.UTC)
+
+func typedFields() []Field {
+	return []Field{
+		String("path", "/a b"),
+		Int("status", 404),
+		Float64("ratio", 0.25),
+		Bool("cached", true),
+		Duration("took", 1500*time.Millisecond),
+		Time("at", typedAt),
+		Err(errors.New("not found")),
+		Object("user", user{id: 7, name: "ann"}),
+		Any("tags", []string{"x"}),
+	}
+}
+
+func TestTypedFields_Text(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(&buf, &buf, INFO)
+
+	log.Infow("request done", typedFields()...)
+
+	assert.Contains(t, buf.String(), ` INFO request done path="/a b" status=404 ratio=0.25 cached=true took=1.5s `+
+		`at=2026-03-01T12:30:00.0000005Z error="not found" user.id=7 user.name=ann tags=[x]`+"\n")
+}
+
+func TestTypedFields_Logfmt(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(Encoded(&buf, LogfmtEncoder{}), nil, INFO)
+
+	log.Infow("done", String("a key", "v"), Object("user", Fields{Int("id", 1)}))
+
+	assert.Contains(t, buf.String(), `msg=done a_key=v user.id=1`+"\n")
+}
+
+func TestTypedFields_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriters(Encoded(&buf, JSONEncoder{}), nil, INFO)
+
+	log.Infow("request done", append(typedFields(), Float64("nan", math.NaN()))...)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "/a b", entry["path"])
+	assert.Equal(t, 404.0, entry["status"])
+	assert.Equal(t, 0.25, entry["ratio"])
+	assert.Equal(t, true, entry["cached"])
+	assert.Equal(t, "1.5s", entry["took"])
+	assert.Equal(t, "2026-03-01T12:30:00.0000005Z", entry["at"])
+	assert.Equal(t, "not found", entry["error"])
+	assert.Equal(t, map[string]interface{}{"id": 7.0, "name": "ann"}, entry["user"])
+	assert.Equal(t, "[x]", entry["tags"])
+	assert.Equal(t, "NaN", entry["nan"])
+}
+
+func TestTypedFields_GELF(t *testing.T) {
+	var buf bytes.Buffer
+	GELFEncoder{Host: "h"}.Encode(&buf, &Record{Time: typedAt, Level: INFO, Message: "m", Fields: typedFields()})
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, 404.0, entry["_status"])
+	assert.Equal(t, "true", entry["_cached"])
+	assert.Equal(t, 7.0, entry["_user.id"])
+	assert.Equal(t, "ann", entry["_user.name"])
+}
+
+func TestTypedFields_Interface(t *testing.T) {
+	values := make([]interface{}, 0)
+	for _, f := range typedFields() {
+		values = append(values, f.Interface())
+	}
+	assert.Equal(t, "/a b", values[0])
+	assert.Equal(t, int64(404), values[1])
+	assert.Equal(t, 0.25, values[2])
+	assert.Equal(t, true, values[3])
+	assert.Equal(t, 1500*time.Millisecond, values[4])
+	assert.True(t, typedAt.Equal(values[5].(time.Time)))
+	assert.EqualError(t, values[6].(error), "not found")
+
+	assert.Equal(t, String("k", "v"), Any("k", "v"))
+	assert.Equal(t, Int("k", 3), Any("k", 3))
+	assert.Equal(t, Duration("k", time.Second), Any("k", time.Second))
+	assert.Equal(t, Field{Key: "k", Value: struct{}{}}, Any("k", struct{}{}))
+
+	ancient := time.Date(1200, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, ancient, Time("k", ancient).Interface())
+}
+
+func TestLog_TrailingFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := Named(NewWithWriters(&buf, &buf, INFO), "api")
+
+	log.Log(INFO, "user %s logged in", "ann", Int("attempt", 2), Bool("mfa", true))
+
+	assert.Contains(t, buf.String(), "INFO user ann logged in logger=api attempt=2 mfa=true\n")
+	assert.NotContains(t, buf.String(), "EXTRA")
+}
+
+func TestWrappedLoggers_TrailingFields(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewWithWriters(&buf, &buf, INFO)
+	wrapped := []Logger{
+		Named(base, "x"),
+		With(base, String("req", "1")),
+		Redact(base, DefaultRedaction),
+		Filter(base, func(Record) bool { return true }),
+		NewFlightRecorder(base),
+		Composite(base),
+	}
+	for i, log := range wrapped {
+		buf.Reset()
+		log.LogOnce(INFO, fmt.Sprint("key", i), "hello %s", "a", String("f", "v"))
+		log.InfoEvery(fmt.Sprint("every", i), time.Minute, "every %s", "b", Int("n", 1))
+		log.Warn("warn %s", "c", Bool("ok", true))
+
+		assert.Contains(t, buf.String(), "INFO hello a", "logger %d", i)
+		assert.Contains(t, buf.String(), " f=v", "logger %d", i)
+		assert.Contains(t, buf.String(), " n=1", "logger %d", i)
+		assert.Contains(t, buf.String(), " ok=true", "logger %d", i)
+		assert.NotContains(t, buf.String(), "EXTRA", "logger %d", i)
+	}
+}
+
+func TestError_TrailingFields(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewWithWriters(&buf, &buf, INFO)
+	for i, log := range []Logger{base, Composite(base), Named(base, "x")} {
+		buf.Reset()
+		err := log.Error("failed %s", "x", String("k", "v"))
+
+		assert.EqualError(t, err, "failed x", "logger %d", i)
+		assert.Contains(t, buf.String(), "ERROR failed x", "logger %d", i)
+		assert.Contains(t, buf.String(), " k=v\n", "logger %d", i)
+		assert.NotContains(t, buf.String(), "EXTRA", "logger %d", i)
+	}
+}
+
+func TestErrorw(t *testing.T) {
+	var buf bytes.Buffer
+	log := Composite(NewWithWriters(&buf, &buf, INFO))
+	cause := errors.New("timeout")
+
+	err := log.Errorw("fetch failed", String("url", "http://x"), Err(cause))
+	assert.EqualError(t, err, "fetch failed: timeout")
+	assert.True(t, errors.Is(err, cause))
+	assert.Contains(t, buf.String(), `ERROR fetch failed url=http://x error=timeout`)
+
+	assert.EqualError(t, log.Errorw("100% broken"), "100% broken")
+	assert.Contains(t, buf.String(), "ERROR 100% broken\n")
+}
+
+func TestTypedFields_RedactAndFilter(t *testing.T) {
+	var buf bytes.Buffer
+	log := Redact(NewWithWriters(&buf, &buf, INFO), Redaction{
+		Rules:      []RedactionRule{BearerTokenRule},
+		DenyFields: []string{"password"},
+	})
+	log = Filter(log, Not(FieldEquals("status", "200")))
+
+	log.Infow("ok", Int("status", 200))
+	log.Infow("login", Int("status", 401), String("auth", "Bearer abc.def"),
+		Object("user", Fields{String("password", "secret"), Int("id", 1)}))
+
+	out := buf.String()
+	assert.NotContains(t, out, "ok")
+	assert.Contains(t, out, "status=401")
+	assert.NotContains(t, out, "abc.def")
+	assert.NotContains(t, out, "secret")
+	assert.Contains(t, out, "user.id=1")
+}
+
+func TestDefault_StructuredLogging(t *testing.T) {
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+	var buf bytes.Buffer
+	SetWriters(&buf, &buf, DEBUG)
+
+	Tracew("hidden")
+	Debugw("debug", Int("n", 1))
+	Infow("info")
+	Warnw("warn")
+	Logw(INFO, "logw")
+	assert.Error(t, Errorw("error"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Regexp(t, regexp.MustCompile(`DEBUG debug n=1$`), lines[0])
+}
+
+func TestAllocs_TypedFields(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool does not reuse buffers reliably under -race")
+	}
+	log := NewWithWriters(ioutil.Discard, ioutil.Discard, INFO)
+	log.Info("warm up the buffer pool")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Debugw("disabled", String("k", "v"), Int("n", 1))
+	})
+	assert.Equal(t, 1.0, allocs, "only the caller's field slice")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		log.Infow("enabled", String("k", "v"), Int("n", 1), Bool("b", true), Float64("f", 0.5))
+	})
+	assert.Equal(t, 1.0, allocs, "only the caller's field slice")
+}
@@ -0,0 +1,126 @@
+package glog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ConfigWatcher re-reads a config file when it changes and swaps the default logger. Create it with WatchConfig.
+type ConfigWatcher struct {
+	path     string
+	interval time.Duration
+	onError  func(error)
+
+	mu      sync.Mutex
+	files   *fileSet
+	data    []byte
+	modTime time.Time
+	size    int64
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// WatchConfig loads the config file at path (see ConfigureFile), installs it as Default() and polls the file every
+// interval (default 1s) for changes. File handles of sinks whose path is unchanged are kept open across reloads; others
+// are closed, and a logger kept from before the reload (e.g. a With child) reopens its file on the next write. Load or
+// build errors after the first are passed to onError (default: logged with Error) and leave the current logger in
+// place. If the first load fails, the error is returned and nothing is watched.
+func WatchConfig(path string, interval time.Duration, onError func(error)) (*ConfigWatcher, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w := &ConfigWatcher{
+		path:     path,
+		interval: interval,
+		onError:  onError,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if w.onError == nil {
+		w.onError = func(err error) {
+			_ = Error("Can't reload log config %s: %v", path, err)
+		}
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// Reload re-reads the file now and swaps the default logger if its content changed.
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	if w.data != nil && bytes.Equal(data, w.data) {
+		return nil
+	}
+
+	cfg, err := loadConfigData(w.path, data)
+	if err != nil {
+		return err
+	}
+	files, err := install(cfg)
+	if err != nil {
+		return err
+	}
+	w.files = files
+	w.data = data
+	return nil
+}
+
+// Close stops watching. The current default logger and its files stay in use.
+func (w *ConfigWatcher) Close() error {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+	return nil
+}
+
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if !w.changed() {
+				continue
+			}
+			if err := w.Reload(); err != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
+
+// changed reports whether the file's modification time or size differs from the last load.
+// A missing file (e.g. mid-rename by an editor) is not a change.
+func (w *ConfigWatcher) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+}
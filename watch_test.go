@@ -0,0 +1,127 @@
+package glog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, path string, content string) {
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	// Make sure the poller sees a new modification time even on coarse-grained filesystems.
+	future := time.Now().Add(time.Duration(len(content)) * time.Second)
+	assert.NoError(t, os.Chtimes(path, future, future))
+}
+
+func fileConfig(path string, level string) string {
+	return fmt.Sprintf("sinks:\n  - type: file\n    path: %s\n    level: %s\n", path, level)
+}
+
+func TestWatchConfig_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "log.yaml")
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	writeConfig(t, cfgPath, fileConfig(first, "info"))
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	w, err := WatchConfig(cfgPath, 5*time.Millisecond, nil)
+	assert.NoError(t, err)
+	defer w.Close()
+	Info("to first")
+
+	writeConfig(t, cfgPath, fileConfig(second, "debug"))
+	assert.Eventually(t, IsDebug, time.Second, 5*time.Millisecond)
+	Debug("to second")
+
+	data, _ := ioutil.ReadFile(first)
+	assert.Contains(t, string(data), "to first")
+	data, _ = ioutil.ReadFile(second)
+	assert.Contains(t, string(data), "to second")
+}
+
+func TestWatchConfig_KeepsUnchangedFileHandles(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "log.yaml")
+	logPath := filepath.Join(dir, "app.log")
+	writeConfig(t, cfgPath, fileConfig(logPath, "info"))
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	w, err := WatchConfig(cfgPath, time.Hour, nil)
+	assert.NoError(t, err)
+	defer w.Close()
+	before := w.files.files[filepath.Clean(logPath)]
+
+	writeConfig(t, cfgPath, fileConfig(logPath, "debug"))
+	assert.NoError(t, w.Reload())
+
+	assert.True(t, IsDebug())
+	assert.Same(t, before, w.files.files[filepath.Clean(logPath)])
+	Debug("same handle")
+	data, _ := ioutil.ReadFile(logPath)
+	assert.Contains(t, string(data), "same handle")
+}
+
+func TestWatchConfig_ParseErrorKeepsCurrentLogger(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "log.yaml")
+	logPath := filepath.Join(dir, "app.log")
+	writeConfig(t, cfgPath, fileConfig(logPath, "info"))
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	var mu sync.Mutex
+	var reported error
+	w, err := WatchConfig(cfgPath, 5*time.Millisecond, func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = err
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	writeConfig(t, cfgPath, "sinks: [type: file")
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return reported != nil
+	}, time.Second, 5*time.Millisecond)
+
+	Info("still here")
+	data, _ := ioutil.ReadFile(logPath)
+	assert.Contains(t, string(data), "still here")
+}
+
+func TestWatchConfig_InitialErrorIsReturned(t *testing.T) {
+	_, err := WatchConfig(filepath.Join(t.TempDir(), "missing.yaml"), time.Second, nil)
+	assert.Error(t, err)
+}
+
+func TestWatchConfig_OldLoggerReopensRemovedFile(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "log.yaml")
+	first := filepath.Join(dir, "first.log")
+	writeConfig(t, cfgPath, fileConfig(first, "info"))
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	w, err := WatchConfig(cfgPath, time.Hour, nil)
+	assert.NoError(t, err)
+	defer w.Close()
+	old := Default()
+	handle := w.files.files[filepath.Clean(first)]
+
+	writeConfig(t, cfgPath, fileConfig(filepath.Join(dir, "second.log"), "info"))
+	assert.NoError(t, w.Reload())
+	assert.Nil(t, handle.f, "removed file is closed")
+
+	old.Info("from a kept logger")
+	data, _ := ioutil.ReadFile(first)
+	assert.Contains(t, string(data), "from a kept logger")
+	assert.Nil(t, old.(ErrorHandlerSetter).LastWriteError())
+	assert.NoError(t, handle.Close())
+}
@@ -0,0 +1,62 @@
+package glog
+
+import "context"
+
+// With returns a child Logger that adds fields to every record logged through it, e.g. a request ID. Fields of nested
+// children follow their parent's. Children share their parent's LogOnce/LogEvery keys, so a per-request child still logs
+// a key once.
+func With(logger Logger, fields ...Field) Logger {
+	if len(fields) == 0 {
+		return logger
+	}
+	if d, ok := logger.(derived); ok {
+		if parent, ok := d.core.(withFields); ok {
+			merged := make([]Field, 0, len(parent.fields)+len(fields))
+			merged = append(append(merged, parent.fields...), fields...)
+			return derived{core: withFields{next: parent.next, fields: merged}, next: parent.next, keys: d.keys}
+		}
+	}
+	return newDerived(withFields{next: logger, fields: fields}, logger)
+}
+
+type withFields struct {
+	next   Logger
+	fields []Field
+}
+
+func (w withFields) IsEnabled(logLevel LogLevel) bool {
+	return w.next.IsEnabled(logLevel)
+}
+
+func (w withFields) logFields(logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	if logLevel != PANIC && logLevel != FATAL && !w.next.IsEnabled(logLevel) {
+		return
+	}
+	if len(fields) > 0 {
+		fields = append(append(make([]Field, 0, len(w.fields)+len(fields)), w.fields...), fields...)
+	} else {
+		fields = w.fields
+	}
+	logWithFields(w.next, logLevel, fields, format, a...)
+}
+
+func (w withFields) logRecord(r *Record) {
+	withParent := *r
+	withParent.Fields = append(append(make([]Field, 0, len(w.fields)+len(r.Fields)), w.fields...), r.Fields...)
+	logRecord(w.next, &withParent)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, for FromContext.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored by NewContext, or the default logger.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return logger
+	}
+	return Default()
+}
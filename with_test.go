@@ -0,0 +1,54 @@
+package glog
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWith_AddsFieldsAndNests(t *testing.T) {
+	var buf bytes.Buffer
+	log := With(With(NewWithWriters(&buf, &buf, INFO), Field{Key: "a", Value: 1}), Field{Key: "b", Value: 2})
+
+	log.Info("hello")
+	_ = log.Error("failed")
+
+	assert.Contains(t, buf.String(), "INFO hello a=1 b=2\n")
+	assert.Contains(t, buf.String(), "ERROR failed a=1 b=2\n")
+	assert.IsType(t, logger{}, log.(derived).core.(withFields).next, "nested With collapses into one wrapper")
+}
+
+func TestWith_SharesOnceKeysWithParent(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewWithWriters(&buf, &buf, INFO)
+	handler := HTTPMiddleware(base)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).WarnOnce("deprecated-endpoint", "endpoint is deprecated")
+	}))
+
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/old", nil))
+	}
+	base.WarnOnce("deprecated-endpoint", "endpoint is deprecated")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "endpoint is deprecated"))
+}
+
+func TestFromContext_DefaultsToDefaultLogger(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	SetWriters(&out, &errBuf, INFO)
+	defer SetWriters(os.Stdout, os.Stderr, INFO)
+
+	FromContext(context.Background()).Info("default")
+	assert.Contains(t, out.String(), "INFO default")
+
+	var buf bytes.Buffer
+	ctx := NewContext(context.Background(), NewWithWriters(&buf, &buf, INFO))
+	FromContext(ctx).Info("scoped")
+	assert.Contains(t, buf.String(), "INFO scoped")
+}
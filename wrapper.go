@@ -0,0 +1,208 @@
+package glog
+
+import (
+	"fmt"
+	"time"
+)
+
+// loggerCore is what a wrapping logger must provide; derived turns it into a full Logger.
+type loggerCore interface {
+	IsEnabled(logLevel LogLevel) bool
+	logFields(logLevel LogLevel, fields []Field, format string, a ...interface{})
+	recordLogger
+}
+
+// derived implements Logger on top of a loggerCore, for wrappers around another Logger (e.g. Redact).
+// SetLevel, SetColor and SetErrorHandler are passed to next when it supports them.
+type derived struct {
+	core loggerCore
+	next Logger
+	keys *keyTable
+}
+
+// newDerived shares next's LogOnce/LogEvery keys when it has them, so a key logs once however the logger is wrapped.
+func newDerived(core loggerCore, next Logger) derived {
+	if owner, ok := next.(keyOwner); ok {
+		return derived{core: core, next: next, keys: owner.onceKeys()}
+	}
+	return derived{core: core, next: next, keys: newKeyTable(keyTableSize)}
+}
+
+// keyOwner is a logger with a LogOnce/LogEvery key table.
+type keyOwner interface {
+	onceKeys() *keyTable
+}
+
+func (d derived) onceKeys() *keyTable {
+	return d.keys
+}
+
+type derivedOutput struct {
+	logLevel LogLevel
+	logger   derived
+}
+
+func (o derivedOutput) Printf(format string, a ...interface{}) {
+	o.logger.Log(o.logLevel, format, a...)
+}
+
+func (d derived) Debug(format string, a ...interface{}) {
+	d.Log(DEBUG, format, a...)
+}
+
+func (d derived) IsDebug() bool {
+	return d.core.IsEnabled(DEBUG)
+}
+
+func (d derived) DebugLogger() Output {
+	return d.GetOutput(DEBUG)
+}
+
+func (d derived) Trace(format string, a ...interface{}) {
+	d.Log(TRACE, format, a...)
+}
+
+func (d derived) TraceLogger() Output {
+	return d.GetOutput(TRACE)
+}
+
+func (d derived) IsTrace() bool {
+	return d.core.IsEnabled(TRACE)
+}
+
+func (d derived) Warn(format string, a ...interface{}) {
+	d.Log(WARN, format, a...)
+}
+
+func (d derived) IsWarn() bool {
+	return d.core.IsEnabled(WARN)
+}
+
+func (d derived) Info(format string, a ...interface{}) {
+	d.Log(INFO, format, a...)
+}
+
+func (d derived) IsInfo() bool {
+	return d.core.IsEnabled(INFO)
+}
+
+func (d derived) Error(format string, a ...interface{}) error {
+	fields, a := splitFields(a)
+	err := fmt.Errorf(format, a...)
+	d.core.logFields(ERROR, fields, "%s", err)
+	return err
+}
+
+func (d derived) ErrorErr(err error, format string, a ...interface{}) error {
+	wrapped := wrapError(err, format, a...)
+	d.core.logFields(ERROR, errorFields(err), "%s", wrapped)
+	return wrapped
+}
+
+func (d derived) IsError() bool {
+	return d.core.IsEnabled(ERROR)
+}
+
+func (d derived) Log(logLevel LogLevel, format string, a ...interface{}) {
+	if logLevel != PANIC && logLevel != FATAL && !d.core.IsEnabled(logLevel) {
+		return
+	}
+	fields, a := splitFields(a)
+	d.core.logFields(logLevel, fields, format, a...)
+}
+
+func (d derived) Logw(logLevel LogLevel, msg string, fields ...Field) {
+	format, args := messageFormat(msg)
+	d.core.logFields(logLevel, fields, format, args...)
+}
+
+func (d derived) Tracew(msg string, fields ...Field) {
+	d.Logw(TRACE, msg, fields...)
+}
+
+func (d derived) Debugw(msg string, fields ...Field) {
+	d.Logw(DEBUG, msg, fields...)
+}
+
+func (d derived) Infow(msg string, fields ...Field) {
+	d.Logw(INFO, msg, fields...)
+}
+
+func (d derived) Warnw(msg string, fields ...Field) {
+	d.Logw(WARN, msg, fields...)
+}
+
+func (d derived) Errorw(msg string, fields ...Field) error {
+	d.Logw(ERROR, msg, fields...)
+	return fieldsError(msg, fields)
+}
+
+func (d derived) logFields(logLevel LogLevel, fields []Field, format string, a ...interface{}) {
+	d.core.logFields(logLevel, fields, format, a...)
+}
+
+func (d derived) logRecord(r *Record) {
+	d.core.logRecord(r)
+}
+
+func (d derived) IsEnabled(logLevel LogLevel) bool {
+	return d.core.IsEnabled(logLevel)
+}
+
+func (d derived) GetOutput(logLevel LogLevel) Output {
+	return derivedOutput{logLevel: logLevel, logger: d}
+}
+
+func (d derived) Panic(format string, a ...interface{}) {
+	d.Log(PANIC, format, a...)
+}
+
+func (d derived) Fatal(format string, a ...interface{}) {
+	d.Log(FATAL, format, a...)
+}
+
+func (d derived) LogOnce(logLevel LogLevel, key string, format string, a ...interface{}) {
+	d.LogEvery(logLevel, key, 0, format, a...)
+}
+
+func (d derived) LogEvery(logLevel LogLevel, key string, every time.Duration, format string, a ...interface{}) {
+	if !d.core.IsEnabled(logLevel) || !d.keys.allow(key, every, time.Now()) {
+		return
+	}
+	d.Log(logLevel, format, a...)
+}
+
+func (d derived) WarnOnce(key string, format string, a ...interface{}) {
+	d.LogOnce(WARN, key, format, a...)
+}
+
+func (d derived) InfoEvery(key string, every time.Duration, format string, a ...interface{}) {
+	d.LogEvery(INFO, key, every, format, a...)
+}
+
+func (d derived) SetLevel(logLevel LogLevel) {
+	if setter, ok := d.next.(LevelSetter); ok {
+		setter.SetLevel(logLevel)
+	}
+}
+
+func (d derived) SetColor(mode ColorMode, palette ...Palette) {
+	if setter, ok := d.next.(ColorSetter); ok {
+		setter.SetColor(mode, palette...)
+	}
+}
+
+func (d derived) SetErrorHandler(handler ErrorHandler) {
+	if setter, ok := d.next.(ErrorHandlerSetter); ok {
+		setter.SetErrorHandler(handler)
+	}
+}
+
+func (d derived) LastWriteError() error {
+	err, _ := latestWriteError(d.next)
+	return err
+}
+
+func (d derived) lastWriteError() (error, time.Time) {
+	return latestWriteError(d.next)
+}